@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoveryUnaryInterceptor turns a panic inside a unary handler (e.g. a nil
+// neo4jClient that the rest of the code tolerates as "unavailable" but some
+// new code path dereferences directly) into a codes.Internal error instead
+// of crashing the process, logging the stack trace so it's still debuggable.
+func recoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor is recoveryUnaryInterceptor's streaming
+// equivalent, for WatchPermissions/StreamPermissionChecks/
+// StreamLookupResources.
+func recoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// checkRequest is satisfied by any request message carrying the
+// actor/resource/permission triple (PermissionRequest, and the per-item
+// TestCase/WatchTarget shapes), so loggingUnaryInterceptor can log them
+// without a type switch over every RPC's request type.
+type checkRequest interface {
+	GetActor() string
+	GetResource() string
+	GetPermission() string
+}
+
+// loggingUnaryInterceptor emits one structured log line per unary RPC with
+// the method, actor/resource/permission (when the request carries them),
+// latency, and outcome - the basic fields needed to correlate a slow or
+// failing check back to who asked for what.
+func loggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		latency := time.Since(start)
+
+		if fields, ok := req.(checkRequest); ok {
+			log.Printf("rpc=%s actor=%q resource=%q permission=%q latency_ms=%d error=%v",
+				info.FullMethod, fields.GetActor(), fields.GetResource(), fields.GetPermission(), latency.Milliseconds(), err)
+		} else {
+			log.Printf("rpc=%s latency_ms=%d error=%v", info.FullMethod, latency.Milliseconds(), err)
+		}
+
+		return resp, err
+	}
+}
+
+// loggingStreamInterceptor is loggingUnaryInterceptor's streaming
+// equivalent. A stream carries many request messages (or none, for
+// server-streaming RPCs), so it logs only the method and the stream's total
+// duration rather than per-message fields.
+func loggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		log.Printf("rpc=%s latency_ms=%d error=%v", info.FullMethod, time.Since(start).Milliseconds(), err)
+		return err
+	}
+}
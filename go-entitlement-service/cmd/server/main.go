@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
+	"runtime/debug"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +18,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/adityakumar/labs/go-entitlement-service/internal/audit"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"github.com/adityakumar/labs/go-entitlement-service/internal/fixtures"
 	graphql "github.com/adityakumar/labs/go-entitlement-service/internal/graphql"
 	neo4j "github.com/adityakumar/labs/go-entitlement-service/internal/neo4j"
 	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
@@ -24,6 +31,47 @@ import (
 type server struct {
 	pb.UnimplementedEntitlementServiceServer
 	svc *service.Service
+
+	// readOnly rejects mutating RPCs (LoadFixtures, and any future
+	// WriteRelationship/schema-update RPC) with codes.Unavailable instead of
+	// touching a backend, the same pattern SpiceDB's own test server uses to
+	// protect a server backing a read replica or a frozen demo environment.
+	readOnly bool
+
+	auditSink   audit.Sink
+	auditBuffer *audit.RingBuffer
+}
+
+// errIfReadOnly is called at the top of every mutating RPC handler.
+func (s *server) errIfReadOnly() error {
+	if !s.readOnly {
+		return nil
+	}
+	return entitlementerrors.New(entitlementerrors.ErrReadOnly, fmt.Errorf("service read-only"))
+}
+
+// recordAudit emits one audit.Record for a permission check, whether or not
+// it succeeded - a failed check still reveals "actor asked about resource"
+// and belongs in the trail. Sink errors are logged, not propagated, so
+// audit logging never holds up the permission check it's describing.
+func (s *server) recordAudit(ctx context.Context, req *pb.PermissionRequest, resp *pb.PermissionResponse, latency time.Duration) {
+	if s.auditSink == nil || req == nil {
+		return
+	}
+
+	var decision bool
+	var permissionship int32
+	var backendName string
+	if resp != nil {
+		decision = resp.HasPermission
+		permissionship = resp.Permissionship
+		backendName = service.BackendNameOf(resp.Implementation)
+	}
+
+	rec := audit.NewRecord(req.Actor, req.Resource, req.Permission, req.Context, decision, permissionship, backendName, latency)
+	if err := s.auditSink.Record(ctx, rec); err != nil {
+		log.Printf("audit sink error: %v", err)
+	}
 }
 
 func (s *server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
@@ -35,7 +83,10 @@ func (s *server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthR
 }
 
 func (s *server) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	return s.svc.CheckPermission(ctx, req)
+	start := time.Now()
+	resp, err := s.svc.CheckPermission(ctx, req)
+	s.recordAudit(ctx, req, resp, time.Since(start))
+	return resp, err
 }
 
 func (s *server) CheckBulkPermissions(ctx context.Context, req *pb.BulkPermissionRequest) (*pb.BulkPermissionResponse, error) {
@@ -69,8 +120,23 @@ func (s *server) CheckBulkPermissions(ctx context.Context, req *pb.BulkPermissio
 			defer wg.Done()
 			sem <- struct{}{} // Acquire
 			defer func() { <-sem }() // Release
+			// A panicking backend call must not take the whole bulk request
+			// down with it - recover it into the same per-item error slot a
+			// returned error would have used.
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("panic checking permission %d: %v\n%s", idx, r, debug.Stack())
+					responses[idx] = &pb.PermissionResponse{
+						HasPermission: false,
+						ErrorMessage:  fmt.Sprintf("panic: %v", r),
+					}
+					atomic.AddInt32(&errorCount, 1)
+				}
+			}()
 
+			itemStart := time.Now()
 			resp, err := s.svc.CheckPermission(ctx, request)
+			s.recordAudit(ctx, request, resp, time.Since(itemStart))
 			if err != nil {
 				responses[idx] = &pb.PermissionResponse{
 					HasPermission: false,
@@ -95,6 +161,75 @@ func (s *server) CheckBulkPermissions(ctx context.Context, req *pb.BulkPermissio
 	}, nil
 }
 
+// BulkCheckPermission batches requests into one round-trip per backend
+// (service.Service.BulkCheckPermission) instead of CheckBulkPermissions'
+// N concurrent single-check calls.
+func (s *server) BulkCheckPermission(ctx context.Context, req *pb.BulkPermissionRequest) (*pb.BulkPermissionResponse, error) {
+	return s.bulkCheckPermission(ctx, req.Requests)
+}
+
+// StreamBulkCheckPermission is BulkCheckPermission over a client stream:
+// each message is one page of requests, answered with one
+// BulkPermissionResponse per page over the same connection, so a caller
+// paging through many batches (e.g. validating a day's transactions 200 at
+// a time) pays one connection setup instead of reopening per page.
+func (s *server) StreamBulkCheckPermission(stream pb.EntitlementService_StreamBulkCheckPermissionServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.bulkCheckPermission(stream.Context(), req.Requests)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// bulkCheckPermission is BulkCheckPermission/StreamBulkCheckPermission's
+// shared body: route requests through service.Service.BulkCheckPermission
+// and tally the per-page success/error counts the RPC response carries.
+func (s *server) bulkCheckPermission(ctx context.Context, requests []*pb.PermissionRequest) (*pb.BulkPermissionResponse, error) {
+	if len(requests) == 0 {
+		return &pb.BulkPermissionResponse{
+			Responses:    []*pb.PermissionResponse{},
+			TotalTimeMs:  0,
+			SuccessCount: 0,
+			ErrorCount:   0,
+		}, nil
+	}
+
+	startTime := time.Now()
+	responses, err := s.svc.BulkCheckPermission(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	totalTime := time.Since(startTime).Milliseconds()
+
+	var successCount, errorCount int32
+	for _, resp := range responses {
+		if resp.ErrorMessage != "" {
+			errorCount++
+		} else {
+			successCount++
+		}
+	}
+
+	return &pb.BulkPermissionResponse{
+		Responses:    responses,
+		TotalTimeMs:  float64(totalTime),
+		SuccessCount: successCount,
+		ErrorCount:   errorCount,
+	}, nil
+}
+
 func (s *server) StreamPermissionChecks(stream pb.EntitlementService_StreamPermissionChecksServer) error {
 	for {
 		req, err := stream.Recv()
@@ -119,6 +254,120 @@ func (s *server) StreamPermissionChecks(stream pb.EntitlementService_StreamPermi
 	}
 }
 
+func (s *server) WatchPermissions(req *pb.WatchRequest, stream pb.EntitlementService_WatchPermissionsServer) error {
+	events, err := s.svc.WatchPermissions(stream.Context(), req.Targets, service.BackendNameOf(req.Implementation))
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *server) LookupResources(ctx context.Context, req *pb.LookupResourcesRequest) (*pb.LookupResourcesResponse, error) {
+	items, err := s.svc.LookupResources(ctx, req.Actor, req.ResourceType, req.Permission, req.Context, req.Consistency, service.BackendNameOf(req.Implementation))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LookupResourcesResponse{Resources: items}, nil
+}
+
+func (s *server) StreamLookupResources(req *pb.LookupResourcesRequest, stream pb.EntitlementService_StreamLookupResourcesServer) error {
+	return s.svc.StreamLookupResources(stream.Context(), req.Actor, req.ResourceType, req.Permission, req.Context, req.Consistency, service.BackendNameOf(req.Implementation), func(item *pb.LookupResourcesItem) error {
+		return stream.Send(item)
+	})
+}
+
+func (s *server) LookupSubjects(ctx context.Context, req *pb.LookupSubjectsRequest) (*pb.LookupSubjectsResponse, error) {
+	subjectIDs, err := s.svc.LookupSubjects(ctx, "account", req.Resource, req.Permission, req.SubjectType, req.Context, req.Consistency, service.BackendNameOf(req.Implementation))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LookupSubjectsResponse{SubjectIds: subjectIDs}, nil
+}
+
+// LoadFixtures reseeds every backend named by req.Implementation (or every
+// registered backend, for IMPLEMENTATION_BOTH) from the fixture
+// files at req.SchemaPath/TuplesPath/RelationshipsPath.
+func (s *server) LoadFixtures(ctx context.Context, req *pb.LoadFixturesRequest) (*pb.LoadFixturesResponse, error) {
+	if err := s.errIfReadOnly(); err != nil {
+		return nil, err
+	}
+
+	set, err := fixtures.Load(req.SchemaPath, req.TuplesPath, req.RelationshipsPath)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrValidationFailed, err)
+	}
+
+	var implementations []string
+	if req.Implementation != pb.Implementation_IMPLEMENTATION_BOTH {
+		implementations = []string{service.BackendNameOf(req.Implementation)}
+	}
+
+	loaded, err := s.svc.LoadFixtures(ctx, set.Schema, set.Relationships, implementations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LoadFixturesResponse{
+		RelationshipsLoaded: int32(len(set.Relationships)),
+		BackendsLoaded:      loaded,
+	}, nil
+}
+
+// auditLogResource is the fixed resource can_access_audit_log is checked
+// against; the audit trail isn't scoped per-resource the way permission
+// checks are, so every caller is asking about the same thing.
+const auditLogResource = "audit_log:main"
+
+// GetAuditLog returns the most recent audit records, gated on the caller
+// holding can_access_audit_log (role:finance_ops in the default schema).
+func (s *server) GetAuditLog(ctx context.Context, req *pb.GetAuditLogRequest) (*pb.GetAuditLogResponse, error) {
+	allowed, err := s.svc.CheckPermission(ctx, &pb.PermissionRequest{
+		Actor:      req.Actor,
+		Resource:   auditLogResource,
+		Permission: "can_access_audit_log",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !allowed.HasPermission {
+		return nil, entitlementerrors.New(entitlementerrors.ErrPermissionDenied, fmt.Errorf("actor %q may not read the audit log", req.Actor))
+	}
+
+	if s.auditBuffer == nil {
+		return &pb.GetAuditLogResponse{Entries: []*pb.AuditLogEntry{}}, nil
+	}
+
+	records := s.auditBuffer.Recent(int(req.Limit))
+	entries := make([]*pb.AuditLogEntry, len(records))
+	for i, rec := range records {
+		entries[i] = &pb.AuditLogEntry{
+			TimestampUnixMs: rec.Timestamp.UnixMilli(),
+			Actor:           rec.Actor,
+			Resource:        rec.Resource,
+			Permission:      rec.Permission,
+			ContextKeys:     rec.ContextKeys,
+			Decision:        rec.Decision,
+			Permissionship:  rec.Permissionship,
+			Backend:         rec.Backend,
+			LatencyMs:       rec.LatencyMs,
+		}
+	}
+	return &pb.GetAuditLogResponse{Entries: entries}, nil
+}
+
 func (s *server) Benchmark(ctx context.Context, req *pb.BenchmarkRequest) (*pb.BenchmarkResponse, error) {
 	if len(req.TestCases) == 0 || req.Iterations <= 0 {
 		return &pb.BenchmarkResponse{
@@ -173,13 +422,24 @@ func (s *server) runBenchmarkTest(ctx context.Context, testCase *pb.TestCase, it
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("panic in benchmark iteration %d: %v\n%s", idx, r, debug.Stack())
+					atomic.AddInt32(&failedCount, 1)
+				}
+			}()
 
 			start := time.Now()
+			reqContext := make(map[string]string, len(testCase.Context)+1)
+			for k, v := range testCase.Context {
+				reqContext[k] = v
+			}
+			reqContext["implementation"] = service.BackendNameOf(implementation)
 			permReq := &pb.PermissionRequest{
 				Actor:      testCase.Actor,
 				Resource:   testCase.Resource,
 				Permission: testCase.Permission,
-				Context:    testCase.Context,
+				Context:    reqContext,
 			}
 
 			resp, err := s.svc.CheckPermission(ctx, permReq)
@@ -233,12 +493,30 @@ func main() {
 		port = "50052" // Use different port from SpiceDB (50051)
 	}
 
+	schemaPath := os.Getenv("SPICEDB_SCHEMA_PATH")
+	if schemaPath == "" {
+		schemaPath = "../../../spicedb-model/model.zaml"
+	}
+
 	// Initialize backend clients - SpiceDB, Neo4j, and GraphQL
 	log.Println("Initializing SpiceDB client...")
-	spiceClient := spicedb.NewClient()
+	spiceClient, err := spicedb.NewClient(schemaPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize SpiceDB client: %v", err)
+	}
 
 	log.Println("Initializing Neo4j client...")
-	neo4jClient, err := neo4j.NewClient("bolt://localhost:7687", "neo4j", "password")
+	neo4jConfig := neo4j.Config{Database: os.Getenv("NEO4J_DATABASE")}
+	if size, err := strconv.Atoi(os.Getenv("NEO4J_MAX_POOL_SIZE")); err == nil {
+		neo4jConfig.MaxConnectionPoolSize = size
+	}
+	if ms, err := strconv.Atoi(os.Getenv("NEO4J_CONNECTION_ACQUISITION_TIMEOUT_MS")); err == nil {
+		neo4jConfig.ConnectionAcquisitionTimeout = time.Duration(ms) * time.Millisecond
+	}
+	if depth, err := strconv.Atoi(os.Getenv("NEO4J_MAX_DEPT_DEPTH")); err == nil {
+		neo4jConfig.MaxDeptDepth = depth
+	}
+	neo4jClient, err := neo4j.NewClient("bolt://localhost:7687", "neo4j", "password", neo4jConfig)
 	if err != nil {
 		log.Printf("Warning: Failed to initialize Neo4j client: %v", err)
 		neo4jClient = nil
@@ -247,18 +525,53 @@ func main() {
 	}
 
 	log.Println("Initializing GraphQL client...")
-	graphqlClient := graphql.NewClient()
+	graphqlClient, err := graphql.NewClient(schemaPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize GraphQL client: %v", err)
+	}
 	log.Println("GraphQL client initialized successfully")
 
-	// Initialize service with all backends
+	// Initialize service with all backends registered under their
+	// conventional names (service.BackendSpiceDB/Neo4j/GraphQL); routing
+	// directives reference backends by these names.
 	svc := service.NewService(spiceClient, neo4jClient, graphqlClient)
 
+	readOnly, _ := strconv.ParseBool(os.Getenv("READ_ONLY"))
+	if readOnly {
+		log.Println("Starting in read-only mode: mutating RPCs will be rejected")
+	}
+
+	auditBuffer := audit.NewRingBuffer(1000)
+	sinks := []audit.Sink{audit.NewStdoutSink(), auditBuffer}
+	if path := os.Getenv("AUDIT_LOG_FILE"); path != "" {
+		fileSink, err := audit.NewFileSink(path)
+		if err != nil {
+			log.Fatalf("Failed to open audit log file: %v", err)
+		}
+		sinks = append(sinks, fileSink)
+	}
+	if brokers := os.Getenv("AUDIT_LOG_KAFKA_BROKERS"); brokers != "" {
+		topic := os.Getenv("AUDIT_LOG_KAFKA_TOPIC")
+		if topic == "" {
+			topic = "entitlement-audit-log"
+		}
+		sinks = append(sinks, audit.NewKafkaSink(strings.Split(brokers, ","), topic))
+	}
+
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	grpcServer := grpc.NewServer()
-	pb.RegisterEntitlementServiceServer(grpcServer, &server{svc: svc})
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoveryUnaryInterceptor(), loggingUnaryInterceptor(), entitlementerrors.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(recoveryStreamInterceptor(), loggingStreamInterceptor()),
+	)
+	pb.RegisterEntitlementServiceServer(grpcServer, &server{
+		svc:         svc,
+		readOnly:    readOnly,
+		auditSink:   audit.NewMultiSink(sinks...),
+		auditBuffer: auditBuffer,
+	})
 
 	// Enable reflection for grpcurl testing
 	reflection.Register(grpcServer)
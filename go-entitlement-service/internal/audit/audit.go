@@ -0,0 +1,63 @@
+// Package audit records one entry per permission check (who asked, what for,
+// what was decided) to a pluggable sink, so "who could access X last week"
+// can be answered without re-deriving it from backend logs that weren't
+// designed for that question.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Record is one permission check, trimmed to what's safe to retain
+// long-term: ContextKeys names the caveat context fields the caller
+// supplied (e.g. "amount", "now") without their values, since values can
+// carry PII or other sensitive data that has no business in an audit trail.
+type Record struct {
+	Timestamp      time.Time
+	Actor          string
+	Resource       string
+	Permission     string
+	ContextKeys    []string
+	Decision       bool
+	Permissionship int32
+	Backend        string
+	LatencyMs      float64
+}
+
+// Sink receives one Record per check. Implementations should not block the
+// caller significantly; a slow or unavailable sink must not hold up the
+// permission check it's describing.
+type Sink interface {
+	Record(ctx context.Context, rec Record) error
+}
+
+// contextKeys returns the keys of ctx (not the values - see Record's
+// ContextKeys doc), sorted isn't required since audit consumers treat this
+// as a set, not an ordered list.
+func contextKeys(ctx map[string]string) []string {
+	if len(ctx) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ctx))
+	for k := range ctx {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// NewRecord builds a Record from the fields a check has on hand, redacting
+// context down to its keys.
+func NewRecord(actor, resource, permission string, checkContext map[string]string, decision bool, permissionship int32, backendName string, latency time.Duration) Record {
+	return Record{
+		Timestamp:      time.Now(),
+		Actor:          actor,
+		Resource:       resource,
+		Permission:     permission,
+		ContextKeys:    contextKeys(checkContext),
+		Decision:       decision,
+		Permissionship: permissionship,
+		Backend:        backendName,
+		LatencyMs:      float64(latency.Microseconds()) / 1000.0,
+	}
+}
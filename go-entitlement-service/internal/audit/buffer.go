@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// RingBuffer retains the most recent records in memory so GetAuditLog can
+// serve "what happened recently" without reading back from whatever durable
+// sink (file, Kafka) is configured - those are write-only from the server's
+// perspective.
+type RingBuffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewRingBuffer returns a RingBuffer holding up to capacity records, oldest
+// evicted first.
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{records: make([]Record, capacity)}
+}
+
+// Record appends rec, overwriting the oldest entry once capacity is reached.
+func (b *RingBuffer) Record(ctx context.Context, rec Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = rec
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+	return nil
+}
+
+// Recent returns up to n of the most recently recorded entries, newest
+// first. n <= 0 returns every retained entry.
+func (b *RingBuffer) Recent(n int) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var ordered []Record
+	if b.full {
+		ordered = append(ordered, b.records[b.next:]...)
+		ordered = append(ordered, b.records[:b.next]...)
+	} else {
+		ordered = append(ordered, b.records[:b.next]...)
+	}
+
+	// ordered is oldest-first; reverse it to newest-first.
+	for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	}
+
+	if n > 0 && n < len(ordered) {
+		ordered = ordered[:n]
+	}
+	return ordered
+}
+
+// MultiSink fans a Record out to every underlying sink (e.g. stdout for
+// operators to tail plus a RingBuffer for GetAuditLog to query), continuing
+// past a failing sink so one bad sink doesn't blind the others.
+type MultiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink returns a Sink that writes to every one of sinks.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+func (m *MultiSink) Record(ctx context.Context, rec Record) error {
+	var firstErr error
+	for _, s := range m.sinks {
+		if err := s.Record(ctx, rec); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// StdoutSink writes each Record as one line of JSON to stdout - the default
+// sink, so audit records show up in the same place as every other log line
+// until an operator wires up something durable.
+type StdoutSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewStdoutSink returns a Sink that writes to os.Stdout.
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{out: os.Stdout}
+}
+
+func (s *StdoutSink) Record(ctx context.Context, rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintln(s.out, string(b))
+	return err
+}
+
+// FileSink appends each Record as one line of JSON to a file, for
+// deployments that collect audit records from disk (e.g. a log-shipping
+// sidecar) rather than stdout.
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink opens path for appending, creating it if it doesn't exist.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file %s: %w", path, err)
+	}
+	return &FileSink{f: f}, nil
+}
+
+func (s *FileSink) Record(ctx context.Context, rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintln(s.f, string(b))
+	return err
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// KafkaSink publishes each Record as a JSON message to a Kafka topic, for
+// deployments that want audit records on a durable, fan-out-able stream
+// instead of a local file.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink returns a Sink that publishes to topic on the given brokers.
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (s *KafkaSink) Record(ctx context.Context, rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	return s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(rec.Resource),
+		Value: b,
+	})
+}
+
+// Close flushes and closes the underlying Kafka writer.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,17 @@
+package backend
+
+import (
+	"context"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// BulkBackend is implemented by backends that can answer many requests in a
+// single round-trip (SpiceDB's CheckBulkPermissions, one aliased GraphQL
+// query, or one UNWIND Cypher statement). Results must be returned in the
+// same order as requests. Backends without a native bulk API can skip this;
+// the service falls back to the cached/coalesced single-check path per
+// request.
+type BulkBackend interface {
+	CheckBulkPermissions(ctx context.Context, requests []*pb.PermissionRequest) ([]*pb.PermissionResponse, error)
+}
@@ -0,0 +1,26 @@
+package backend
+
+import "context"
+
+// Relationship is one (resource, relation, subject) tuple a FixtureBackend
+// can load, with an optional caveat payload for relations that attach one -
+// the same shape the SpiceDB schema's relationships take, used as the
+// backend-agnostic fixture format every backend translates into its own
+// native writes.
+type Relationship struct {
+	Resource string
+	Relation string
+	Subject  string
+	Caveat   map[string]interface{}
+}
+
+// FixtureBackend is implemented by backends that can load a schema and
+// relationship fixtures and wipe them back out, so the same test fixtures
+// (see spicedb-model/tests/go) can be driven against every backend instead
+// of just SpiceDB. Not every PermissionBackend needs to implement this;
+// GraphQL shares Neo4j's underlying store rather than loading its own.
+type FixtureBackend interface {
+	LoadSchema(ctx context.Context, schema string) error
+	WriteRelationships(ctx context.Context, rels []Relationship) error
+	ClearAll(ctx context.Context) error
+}
@@ -0,0 +1,20 @@
+package backend
+
+import (
+	"context"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// ResourceLookup is implemented by backends that can answer "which resources
+// can this actor access" and its inverse "who can access this resource"
+// directly (SpiceDB's LookupResources/LookupSubjects), rather than requiring
+// the caller to enumerate resources and call CheckPermission on each one.
+// Not every PermissionBackend needs to implement this; the service layer
+// returns ErrValidationFailed-style "unsupported" when the selected backend
+// doesn't.
+type ResourceLookup interface {
+	LookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency) ([]*pb.LookupResourcesItem, error)
+	StreamLookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency, onItem func(*pb.LookupResourcesItem) error) error
+	LookupSubjects(ctx context.Context, resourceType, resourceID, permission, subjectType string, caveatContext map[string]string, consistency *pb.Consistency) ([]string, error)
+}
@@ -0,0 +1,78 @@
+// Package backend decouples Service from any fixed set of named backends,
+// so new authorization backends can be registered at startup instead of
+// requiring a new case in a hard-coded switch.
+package backend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// PermissionBackend is implemented by every backend (SpiceDB, Neo4j, GraphQL,
+// or future additions) that can answer a single permission check.
+type PermissionBackend interface {
+	CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error)
+}
+
+// Registry holds named backends so routing directives (see routing.go) can
+// reference them by name instead of Service hard-coding a field per backend.
+type Registry struct {
+	mu       sync.RWMutex
+	backends map[string]PermissionBackend
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{backends: make(map[string]PermissionBackend)}
+}
+
+// Register adds backend under name, overwriting any existing registration.
+// A nil backend is accepted so callers can register "not wired up" and get a
+// consistent not-found error rather than a nil-pointer panic at call time.
+func (r *Registry) Register(name string, b PermissionBackend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[name] = b
+}
+
+// Get returns the backend registered under name, or an error if none was
+// registered or it was registered as nil (never successfully initialized).
+func (r *Registry) Get(name string) (PermissionBackend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("no backend registered under name %q", name)
+	}
+	if isNil(b) {
+		return nil, fmt.Errorf("backend %q is not available", name)
+	}
+	return b, nil
+}
+
+// Names returns the names of every registered, non-nil backend.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.backends))
+	for name, b := range r.backends {
+		if !isNil(b) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isNil reports whether b is nil, including a nil pointer wrapped in a
+// non-nil interface (e.g. a nil *neo4j.Client passed as a PermissionBackend).
+func isNil(b PermissionBackend) bool {
+	if b == nil {
+		return true
+	}
+	v := reflect.ValueOf(b)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mode selects how a routing Directive dispatches a check across backends.
+type Mode int
+
+const (
+	// ModeSingle checks exactly one named backend.
+	ModeSingle Mode = iota
+	// ModeShadow checks Primary and returns its result, while firing each
+	// Shadows entry in the background and recording any divergence.
+	ModeShadow
+	// ModeQuorum checks every backend in Backends concurrently and grants
+	// only when at least Threshold of them grant; otherwise it denies. It
+	// errors when fewer than Threshold backends answer at all.
+	ModeQuorum
+	// ModeRace checks every backend in Backends concurrently and returns
+	// the first successful response, cancelling the rest; it errors only
+	// when every backend fails.
+	ModeRace
+)
+
+// Directive is a parsed routing instruction, e.g. "primary:spicedb,shadow:neo4j"
+// or "quorum:spicedb,neo4j,graphql:2".
+type Directive struct {
+	Mode      Mode
+	Primary   string   // ModeSingle, ModeShadow
+	Shadows   []string // ModeShadow
+	Backends  []string // ModeQuorum, ModeRace
+	Threshold int      // ModeQuorum: minimum agreeing responses required
+}
+
+// ParseDirective interprets the `implementation` request field as a routing
+// directive. Recognized forms:
+//
+//	"spicedb"                                  -> ModeSingle
+//	"primary:spicedb,shadow:neo4j"             -> ModeShadow
+//	"quorum:spicedb,neo4j,graphql:2"           -> ModeQuorum, threshold 2
+//	"race:spicedb,neo4j,graphql"               -> ModeRace
+//
+// Any string that doesn't match one of the directive forms is treated as a
+// bare backend name under ModeSingle, preserving backward compatibility with
+// the plain "spicedb"/"neo4j"/"graphql" values used before routing
+// directives existed. The legacy "both" value maps to ModeShadow, with
+// spicedb as primary and neo4j as its shadow.
+func ParseDirective(raw string) (Directive, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Directive{}, fmt.Errorf("empty routing directive")
+	}
+
+	if raw == "both" {
+		return Directive{Mode: ModeShadow, Primary: "spicedb", Shadows: []string{"neo4j"}}, nil
+	}
+
+	if strings.HasPrefix(raw, "primary:") {
+		return parseShadowDirective(raw)
+	}
+	if strings.HasPrefix(raw, "quorum:") {
+		return parseQuorumDirective(raw)
+	}
+	if strings.HasPrefix(raw, "race:") {
+		names := splitNames(strings.TrimPrefix(raw, "race:"))
+		if len(names) < 2 {
+			return Directive{}, fmt.Errorf("race directive needs at least 2 backends, got %v", names)
+		}
+		return Directive{Mode: ModeRace, Backends: names}, nil
+	}
+
+	return Directive{Mode: ModeSingle, Primary: raw}, nil
+}
+
+// parseShadowDirective parses "primary:spicedb,shadow:neo4j[,shadow:graphql]".
+func parseShadowDirective(raw string) (Directive, error) {
+	var d Directive
+	d.Mode = ModeShadow
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, "primary:"):
+			d.Primary = strings.TrimPrefix(part, "primary:")
+		case strings.HasPrefix(part, "shadow:"):
+			d.Shadows = append(d.Shadows, strings.TrimPrefix(part, "shadow:"))
+		default:
+			return Directive{}, fmt.Errorf("unrecognized shadow directive segment %q", part)
+		}
+	}
+
+	if d.Primary == "" {
+		return Directive{}, fmt.Errorf("shadow directive missing primary: %q", raw)
+	}
+	if len(d.Shadows) == 0 {
+		return Directive{}, fmt.Errorf("shadow directive missing at least one shadow: %q", raw)
+	}
+	return d, nil
+}
+
+// parseQuorumDirective parses "quorum:spicedb,neo4j,graphql:2" where the
+// trailing ":N" (if present) sets the required agreeing-response threshold,
+// defaulting to a simple majority of the listed backends.
+func parseQuorumDirective(raw string) (Directive, error) {
+	body := strings.TrimPrefix(raw, "quorum:")
+	fields := strings.Split(body, ":")
+	names := splitNames(fields[0])
+	if len(names) < 2 {
+		return Directive{}, fmt.Errorf("quorum directive needs at least 2 backends, got %v", names)
+	}
+
+	threshold := len(names)/2 + 1
+	if len(fields) > 1 {
+		n, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return Directive{}, fmt.Errorf("invalid quorum threshold %q: %w", fields[1], err)
+		}
+		threshold = n
+	}
+	if threshold < 1 || threshold > len(names) {
+		return Directive{}, fmt.Errorf("quorum threshold %d out of range for %d backends", threshold, len(names))
+	}
+
+	return Directive{Mode: ModeQuorum, Backends: names, Threshold: threshold}, nil
+}
+
+func splitNames(raw string) []string {
+	parts := strings.Split(raw, ",")
+	names := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			names = append(names, p)
+		}
+	}
+	return names
+}
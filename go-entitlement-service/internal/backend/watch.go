@@ -0,0 +1,16 @@
+package backend
+
+import (
+	"context"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// Watcher is implemented by backends that can stream PermissionEvents for a
+// set of watched targets instead of requiring clients to poll
+// CheckPermission. Not every PermissionBackend needs to implement this; the
+// service layer returns an ErrValidationFailed "unsupported" error when the
+// selected backend doesn't.
+type Watcher interface {
+	Watch(ctx context.Context, targets []*pb.WatchTarget) (<-chan *pb.PermissionEvent, error)
+}
@@ -0,0 +1,163 @@
+// Package cache provides a small TTL'd LRU keyed on the tuple that
+// determines a CheckPermission answer, plus singleflight coalescing so a
+// burst of identical concurrent checks only reaches the backend once.
+// Real authorization traffic is bursty and heavily repeats the same tuples
+// (a page re-checking the same account/permission for every row), so this
+// sits in front of every backend's CheckPermission.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// Key identifies a cacheable CheckPermission call. Two requests that differ
+// only in caveat-context key order must hash identically, so Context is
+// summarized via HashContext before building a Key.
+type Key struct {
+	Backend          string
+	Actor            string
+	Resource         string
+	Permission       string
+	ConsistencyToken string
+	ContextHash      string
+}
+
+// HashContext produces a stable hash of a caveat context map regardless of
+// Go's randomized map iteration order.
+func HashContext(context map[string]string) string {
+	if len(context) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(context))
+	for k := range context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ordered := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		ordered = append(ordered, [2]string{k, context[k]})
+	}
+	b, _ := json.Marshal(ordered)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+type entry struct {
+	key       Key
+	value     *pb.PermissionResponse
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// Cache is an LRU of CheckPermission responses with a fixed TTL and a cap on
+// the number of entries, plus singleflight coalescing of concurrent misses
+// for the same key.
+type Cache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	entries  map[Key]*entry
+	order    *list.List // front = most recently used
+
+	group singleflight.Group
+}
+
+// New creates a Cache holding at most capacity entries, each valid for ttl
+// after it was written.
+func New(capacity int, ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[Key]*entry),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached response for key, if present and not expired.
+func (c *Cache) Get(key Key) (*pb.PermissionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		c.removeLocked(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e.elem)
+	return e.value, true
+}
+
+// Set stores value under key, evicting the least-recently-used entry if the
+// cache is at capacity.
+func (c *Cache) Set(key Key, value *pb.PermissionResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.value = value
+		e.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(e.elem)
+		return
+	}
+
+	e := &entry{key: key, value: value, expiresAt: time.Now().Add(c.ttl)}
+	e.elem = c.order.PushFront(e)
+	c.entries[key] = e
+
+	if c.capacity > 0 && len(c.entries) > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.removeLocked(oldest.Value.(*entry))
+		}
+	}
+}
+
+func (c *Cache) removeLocked(e *entry) {
+	delete(c.entries, e.key)
+	c.order.Remove(e.elem)
+}
+
+// GetOrLoad returns the cached response for key if present, otherwise calls
+// load exactly once even if many callers request the same key concurrently
+// (singleflight), caches the result, and returns it to every caller.
+func (c *Cache) GetOrLoad(ctx context.Context, key Key, load func(context.Context) (*pb.PermissionResponse, error)) (*pb.PermissionResponse, error) {
+	if resp, ok := c.Get(key); ok {
+		return resp, nil
+	}
+
+	// singleflight.Group keys are strings; Key is a small fixed-field struct
+	// so using it directly as a map key via %+v-style formatting would be
+	// fragile - use the same ContextHash/fields joined explicitly instead.
+	sfKey := key.Backend + "|" + key.Actor + "|" + key.Resource + "|" + key.Permission + "|" + key.ConsistencyToken + "|" + key.ContextHash
+
+	v, err, _ := c.group.Do(sfKey, func() (interface{}, error) {
+		if resp, ok := c.Get(key); ok {
+			return resp, nil
+		}
+		resp, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, resp)
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*pb.PermissionResponse), nil
+}
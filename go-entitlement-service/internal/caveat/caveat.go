@@ -0,0 +1,329 @@
+// Package caveat type-checks SpiceDB caveat context values against a
+// schema's declared caveat parameter types before they reach the wire. A
+// hand-rolled caveat-name map or a silent "default to 1000.0 on parse
+// failure" (the old graphql.Client behavior) lets a malformed or missing
+// value through as whatever SpiceDB's CEL evaluator makes of it; Context.Build
+// instead rejects it up front as ErrCaveatContextInvalid.
+package caveat
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ParamType is a caveat parameter's declared CEL type, as it appears in a
+// SpiceDB schema's `caveat name(param type, ...) { ... }` declaration.
+type ParamType int
+
+const (
+	ParamInt ParamType = iota
+	ParamUint
+	ParamString
+	ParamBool
+	ParamTimestamp
+	ParamIPAddress
+)
+
+func parseParamType(raw string) (ParamType, error) {
+	switch raw {
+	case "int":
+		return ParamInt, nil
+	case "uint":
+		return ParamUint, nil
+	case "string":
+		return ParamString, nil
+	case "bool":
+		return ParamBool, nil
+	case "timestamp":
+		return ParamTimestamp, nil
+	case "ipaddress":
+		return ParamIPAddress, nil
+	default:
+		return 0, fmt.Errorf("unsupported caveat parameter type %q", raw)
+	}
+}
+
+type definition struct {
+	name   string
+	params map[string]ParamType
+}
+
+// Context type-checks context values against the caveats declared in a
+// loaded SpiceDB schema.
+type Context struct {
+	caveats map[string]definition
+	// fieldTypes aggregates every declared caveat's parameters by name,
+	// for validating check-time context: a permission check can reference
+	// several caveats at once (e.g. a relationship caveat's own parameters
+	// plus a sibling caveat's), so unlike Build there's no single caveat
+	// name to validate the whole map against.
+	fieldTypes map[string]ParamType
+	// fieldCaveats maps each declared parameter back to every caveat that
+	// declares it, so a backend that only learns a field name (e.g.
+	// SpiceDB's PartialCaveatInfo.MissingRequiredContext) can report which
+	// caveat expression it's missing context for. A field can be declared by
+	// more than one caveat (e.g. "amount" in both under_limit and
+	// within_time_and_limit), which is why CaveatForField also takes the
+	// permission under check rather than trusting this map alone.
+	fieldCaveats map[string][]string
+	// permissionCaveats maps each declared permission to the caveats
+	// reachable through the relations referenced in its expression
+	// (transitively, through any permissions it references), so
+	// CaveatForField can prefer the caveat that actually binds the
+	// permission under check over one that merely shares a field name.
+	permissionCaveats map[string][]string
+}
+
+var (
+	caveatDeclRE     = regexp.MustCompile(`(?m)^\s*caveat\s+(\w+)\s*\(([^)]*)\)`)
+	relationCaveatRE = regexp.MustCompile(`(?m)^\s*relation\s+(\w+):.*\bwith\s+(.+)$`)
+	permissionDeclRE = regexp.MustCompile(`(?m)^\s*permission\s+(\w+)\s*=\s*(.+)$`)
+	identifierRE     = regexp.MustCompile(`\w+`)
+)
+
+// Load parses every `caveat name(param type, ...) { ... }` declaration out
+// of a SpiceDB schema and returns a Context that can validate values against
+// them. It does not evaluate caveat expression bodies, only their parameter
+// signatures. It also mines the schema's `relation name: ... with caveat`
+// bindings and `permission name = ...` expressions well enough to tell which
+// caveats a given permission can reach - just enough for CaveatForField to
+// disambiguate a shared field name, not a full schema/type-system parse.
+func Load(schemaText string) (*Context, error) {
+	c := &Context{
+		caveats:           make(map[string]definition),
+		fieldTypes:        make(map[string]ParamType),
+		fieldCaveats:      make(map[string][]string),
+		permissionCaveats: make(map[string][]string),
+	}
+
+	for _, match := range caveatDeclRE.FindAllStringSubmatch(schemaText, -1) {
+		name, rawParams := match[1], match[2]
+		params := make(map[string]ParamType)
+
+		for _, rawParam := range strings.Split(rawParams, ",") {
+			rawParam = strings.TrimSpace(rawParam)
+			if rawParam == "" {
+				continue
+			}
+			fields := strings.Fields(rawParam)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("caveat %s: malformed parameter %q", name, rawParam)
+			}
+			paramName, rawType := fields[0], fields[1]
+			paramType, err := parseParamType(rawType)
+			if err != nil {
+				return nil, fmt.Errorf("caveat %s: %w", name, err)
+			}
+			params[paramName] = paramType
+			c.fieldTypes[paramName] = paramType
+			c.fieldCaveats[paramName] = append(c.fieldCaveats[paramName], name)
+		}
+
+		c.caveats[name] = definition{name: name, params: params}
+	}
+
+	relationCaveats := make(map[string][]string)
+	for _, match := range relationCaveatRE.FindAllStringSubmatch(schemaText, -1) {
+		relation, withClause := match[1], match[2]
+		for _, token := range identifierRE.FindAllString(withClause, -1) {
+			if _, ok := c.caveats[token]; ok {
+				relationCaveats[relation] = append(relationCaveats[relation], token)
+			}
+		}
+	}
+
+	permissionBodies := make(map[string]string)
+	for _, match := range permissionDeclRE.FindAllStringSubmatch(schemaText, -1) {
+		permissionBodies[match[1]] = match[2]
+	}
+	for name := range permissionBodies {
+		c.permissionCaveats[name] = resolvePermissionCaveats(name, permissionBodies, relationCaveats, make(map[string]bool))
+	}
+
+	return c, nil
+}
+
+// resolvePermissionCaveats collects the caveats reachable from permission's
+// body: the caveats any relation it mentions is declared "with", plus
+// whatever any other permission it mentions (directly or transitively)
+// reaches the same way. visiting guards against a permission's expression
+// eventually referencing itself.
+func resolvePermissionCaveats(permission string, bodies map[string]string, relationCaveats map[string][]string, visiting map[string]bool) []string {
+	if visiting[permission] {
+		return nil
+	}
+	visiting[permission] = true
+
+	body, ok := bodies[permission]
+	if !ok {
+		return nil
+	}
+
+	var caveats []string
+	for _, token := range identifierRE.FindAllString(body, -1) {
+		if caveatNames, ok := relationCaveats[token]; ok {
+			caveats = append(caveats, caveatNames...)
+		} else if token != permission {
+			caveats = append(caveats, resolvePermissionCaveats(token, bodies, relationCaveats, visiting)...)
+		}
+	}
+	return caveats
+}
+
+// CaveatForField returns the name of the caveat that declares field, or ""
+// if field isn't a parameter of any caveat in this schema. field can be
+// declared by more than one caveat (e.g. "amount" in both under_limit and
+// within_time_and_limit); when it is, CaveatForField prefers whichever of
+// those caveats permission's own expression actually reaches over the
+// others, instead of whichever caveat Load happened to parse last.
+func (c *Context) CaveatForField(permission, field string) string {
+	candidates := c.fieldCaveats[field]
+	if len(candidates) == 0 {
+		return ""
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	for _, reachable := range c.permissionCaveats[permission] {
+		for _, candidate := range candidates {
+			if reachable == candidate {
+				return candidate
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// ValidateContext type-checks a free-form check-time caveat context (as
+// passed to CheckPermission) against every declared caveat's parameter
+// types. Unlike Build, it doesn't require all of one caveat's parameters to
+// be present: a single check can touch several caveats along the
+// permission's resolution path, each needing only the fields it declares.
+func (c *Context) ValidateContext(values map[string]interface{}) (*structpb.Struct, error) {
+	coerced := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		paramType, ok := c.fieldTypes[key]
+		if !ok {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "unknown caveat context field %q", key)
+		}
+		v, err := coerce(paramType, value)
+		if err != nil {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat context field %q: %v", key, err)
+		}
+		coerced[key] = v
+	}
+
+	s, err := structpb.NewStruct(coerced)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+	}
+	return s, nil
+}
+
+// Build type-checks kv (alternating key, value pairs) against caveatName's
+// declared parameters and returns the resulting context struct. It returns
+// ErrCaveatContextInvalid if caveatName is undeclared, a key is unknown to
+// that caveat, a required parameter is missing, or a value doesn't match its
+// declared type - including a non-RFC3339 value for a timestamp parameter.
+func (c *Context) Build(caveatName string, kv ...interface{}) (*structpb.Struct, error) {
+	def, ok := c.caveats[caveatName]
+	if !ok {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "unknown caveat %q", caveatName)
+	}
+	if len(kv)%2 != 0 {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat %q: odd number of key/value arguments", caveatName)
+	}
+
+	values := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat %q: key %v is not a string", caveatName, kv[i])
+		}
+		paramType, ok := def.params[key]
+		if !ok {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat %q: unknown parameter %q", caveatName, key)
+		}
+
+		coerced, err := coerce(paramType, kv[i+1])
+		if err != nil {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat %q: parameter %q: %v", caveatName, key, err)
+		}
+		values[key] = coerced
+	}
+
+	for name := range def.params {
+		if _, ok := values[name]; !ok {
+			return nil, entitlementerrors.Newf(entitlementerrors.ErrCaveatContextInvalid, "caveat %q: missing required parameter %q", caveatName, name)
+		}
+	}
+
+	s, err := structpb.NewStruct(values)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+	}
+	return s, nil
+}
+
+// coerce converts value to the wire representation expected for paramType,
+// returning an error if value doesn't fit that type. Timestamp values are
+// validated as RFC3339 and passed through as strings, matching how SpiceDB's
+// caveat context already represents them on the wire.
+func coerce(paramType ParamType, value interface{}) (interface{}, error) {
+	switch paramType {
+	case ParamInt, ParamUint:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", v)
+			}
+			return n, nil
+		default:
+			return nil, fmt.Errorf("expected a number, got %T", value)
+		}
+
+	case ParamBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a bool, got %T", value)
+		}
+		return b, nil
+
+	case ParamTimestamp:
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339), nil
+		case string:
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				return nil, fmt.Errorf("expected an RFC3339 timestamp, got %q: %w", v, err)
+			}
+			return v, nil
+		default:
+			return nil, fmt.Errorf("expected an RFC3339 timestamp, got %T", value)
+		}
+
+	case ParamIPAddress, ParamString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		return s, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %v", paramType)
+	}
+}
@@ -0,0 +1,120 @@
+// Package errors defines the typed error taxonomy shared by every backend
+// (SpiceDB, Neo4j, GraphQL) and the service layer, so callers can branch on
+// a stable code instead of pattern-matching error strings.
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Code identifies the class of failure independent of which backend raised it.
+type Code string
+
+const (
+	// ErrValidationFailed means the request itself was malformed (bad actor/resource
+	// format, unknown permission, invalid caveat context, etc).
+	ErrValidationFailed Code = "VALIDATION_FAILED"
+	// ErrBackendUnavailable means the backend (SpiceDB/Neo4j/GraphQL) could not be
+	// reached or returned a transport-level failure.
+	ErrBackendUnavailable Code = "BACKEND_UNAVAILABLE"
+	// ErrSchemaNotFound means the backend has no schema/object type/permission
+	// matching the request (e.g. SpiceDB "object definition not found").
+	ErrSchemaNotFound Code = "SCHEMA_NOT_FOUND"
+	// ErrDeadlineExceeded means the backend call ran past its context deadline.
+	ErrDeadlineExceeded Code = "DEADLINE_EXCEEDED"
+	// ErrCaveatContextInvalid means caveat context was missing, malformed, or
+	// failed type-checking against the schema's declared caveat parameters.
+	ErrCaveatContextInvalid Code = "CAVEAT_CONTEXT_INVALID"
+	// ErrUnauthenticated means the backend rejected our credentials (e.g. SpiceDB
+	// preshared key).
+	ErrUnauthenticated Code = "UNAUTHENTICATED"
+	// ErrConflict means a write raced with another write (e.g. relationship
+	// already exists, stale ZedToken).
+	ErrConflict Code = "CONFLICT"
+	// ErrReadOnly means a mutating RPC was rejected because the server is
+	// running in read-only mode (see cmd/server's READ_ONLY toggle).
+	ErrReadOnly Code = "READ_ONLY"
+	// ErrPermissionDenied means the caller was identified but isn't allowed
+	// to perform the requested operation (e.g. GetAuditLog without
+	// can_access_audit_log), as distinct from ErrUnauthenticated's "we don't
+	// know who you are".
+	ErrPermissionDenied Code = "PERMISSION_DENIED"
+	// ErrNotFound means a request referenced an entity (user, org, role,
+	// grant) that does not exist, as distinct from ErrSchemaNotFound's
+	// "the backend doesn't know this permission/object type at all".
+	ErrNotFound Code = "NOT_FOUND"
+	// ErrAlreadyExists means a create call collided with an entity that's
+	// already there, distinct from ErrConflict's "a write raced another
+	// write".
+	ErrAlreadyExists Code = "ALREADY_EXISTS"
+)
+
+// Error is the typed error every backend should return from CheckPermission and
+// friends instead of embedding prose into PermissionResponse.ErrorMessage.
+type Error struct {
+	Code  Code
+	Cause error
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %v", e.Code, e.Cause)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// New wraps cause under the given code. cause may be nil.
+func New(code Code, cause error) *Error {
+	return &Error{Code: code, Cause: cause}
+}
+
+// Newf wraps a formatted error under the given code.
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Cause: fmt.Errorf(format, args...)}
+}
+
+// CodeOf extracts the Code from err, falling back to ErrBackendUnavailable for
+// untyped errors so callers always get a stable classification.
+func CodeOf(err error) Code {
+	var typed *Error
+	if stderrors.As(err, &typed) {
+		return typed.Code
+	}
+	return ErrBackendUnavailable
+}
+
+// grpcCodes maps each taxonomy code to the gRPC status code a handler should
+// surface to clients.
+var grpcCodes = map[Code]codes.Code{
+	ErrValidationFailed:     codes.InvalidArgument,
+	ErrBackendUnavailable:   codes.Unavailable,
+	ErrSchemaNotFound:       codes.NotFound,
+	ErrDeadlineExceeded:     codes.DeadlineExceeded,
+	ErrCaveatContextInvalid: codes.InvalidArgument,
+	ErrUnauthenticated:      codes.Unauthenticated,
+	ErrConflict:             codes.Aborted,
+	ErrReadOnly:             codes.Unavailable,
+	ErrPermissionDenied:     codes.PermissionDenied,
+	ErrNotFound:             codes.NotFound,
+	ErrAlreadyExists:        codes.AlreadyExists,
+}
+
+// GRPCCode returns the google.golang.org/grpc/codes.Code that should be used
+// for err. Untyped errors map to codes.Unknown.
+func GRPCCode(err error) codes.Code {
+	var typed *Error
+	if stderrors.As(err, &typed) {
+		if c, ok := grpcCodes[typed.Code]; ok {
+			return c
+		}
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,27 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor converts a typed *Error returned by a handler into a
+// google.golang.org/grpc/status.Status carrying the mapped code, so clients
+// can branch on codes.Code instead of parsing error strings.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		var typed *Error
+		if stderrors.As(err, &typed) {
+			return resp, status.Error(GRPCCode(err), typed.Error())
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,124 @@
+// Package fixtures loads the declarative relationship fixtures ("Emma has
+// POA over David's account", "Adi has temporary accountant access", ...)
+// that back both the integration test suite and the LoadFixtures admin RPC,
+// so there's a single source of truth for the test graph instead of a Go
+// literal the RPC path and the test harness have to keep in sync by hand.
+package fixtures
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	"gopkg.in/yaml.v3"
+)
+
+// Set is everything needed to reseed a backend: the schema to load, and the
+// relationships to write after it.
+type Set struct {
+	Schema        string
+	Relationships []backend.Relationship
+}
+
+// Load reads schemaPath (SpiceDB schema text), tuplesPath (a CSV of plain
+// "resource,relation,subject" rows - the common case, with no caveat) and
+// relationshipsPath (a YAML file of relationships that need a caveat
+// context, which doesn't fit tuplesPath's flat rows) into one Set. Any path
+// left empty skips that source instead of erroring.
+func Load(schemaPath, tuplesPath, relationshipsPath string) (*Set, error) {
+	set := &Set{}
+
+	if schemaPath != "" {
+		schemaBytes, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema from %s: %w", schemaPath, err)
+		}
+		set.Schema = string(schemaBytes)
+	}
+
+	if tuplesPath != "" {
+		rels, err := loadTuples(tuplesPath)
+		if err != nil {
+			return nil, err
+		}
+		set.Relationships = append(set.Relationships, rels...)
+	}
+
+	if relationshipsPath != "" {
+		rels, err := loadRelationships(relationshipsPath)
+		if err != nil {
+			return nil, err
+		}
+		set.Relationships = append(set.Relationships, rels...)
+	}
+
+	return set, nil
+}
+
+// loadTuples parses a CSV of "resource,relation,subject" rows with a header
+// row, the flat common case that doesn't need a caveat.
+func loadTuples(path string) ([]backend.Relationship, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tuples file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tuples file %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	rels := make([]backend.Relationship, 0, len(records)-1)
+	for _, record := range records[1:] { // skip header row
+		if len(record) < 3 {
+			return nil, fmt.Errorf("tuples file %s: malformed row %v", path, record)
+		}
+		rels = append(rels, backend.Relationship{
+			Resource: strings.TrimSpace(record[0]),
+			Relation: strings.TrimSpace(record[1]),
+			Subject:  strings.TrimSpace(record[2]),
+		})
+	}
+	return rels, nil
+}
+
+// relationshipsFile is the shape of a YAML relationships fixture file.
+type relationshipsFile struct {
+	Relationships []struct {
+		Resource string                 `yaml:"resource"`
+		Relation string                 `yaml:"relation"`
+		Subject  string                 `yaml:"subject"`
+		Caveat   map[string]interface{} `yaml:"caveat"`
+	} `yaml:"relationships"`
+}
+
+// loadRelationships parses a YAML file of relationships, each optionally
+// carrying a caveat context - the shape tuples.csv's flat rows can't express.
+func loadRelationships(path string) ([]backend.Relationship, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relationships file %s: %w", path, err)
+	}
+
+	var file relationshipsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse relationships file %s: %w", path, err)
+	}
+
+	rels := make([]backend.Relationship, len(file.Relationships))
+	for i, r := range file.Relationships {
+		rels[i] = backend.Relationship{
+			Resource: r.Resource,
+			Relation: r.Relation,
+			Subject:  r.Subject,
+			Caveat:   r.Caveat,
+		}
+	}
+	return rels, nil
+}
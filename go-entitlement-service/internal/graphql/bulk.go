@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// CheckBulkPermissions answers every request in a single GraphQL round-trip
+// by aliasing each request's query as a0, a1, ... in one document, instead of
+// one HTTP request per check. A request whose caveat context fails
+// validation is answered inline as a typed error without being added to the
+// document, so one bad request doesn't fail the rest of the batch.
+func (c *Client) CheckBulkPermissions(ctx context.Context, requests []*pb.PermissionRequest) ([]*pb.PermissionResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	results := make([]*pb.PermissionResponse, len(requests))
+
+	var fields bytes.Buffer
+	variables := make(map[string]interface{}, len(requests)*2)
+	aliases := make(map[int]string, len(requests))
+	var queriedIndices []int
+
+	for i, req := range requests {
+		alias := fmt.Sprintf("a%d", i)
+		field, reqVars, buildErr := c.buildAliasedField(alias, i, req)
+		if buildErr != nil {
+			results[i] = errorResponse(buildErr)
+			continue
+		}
+
+		aliases[i] = alias
+		queriedIndices = append(queriedIndices, i)
+		fields.WriteString(field)
+		fields.WriteString("\n")
+		for k, v := range reqVars {
+			variables[k] = v
+		}
+	}
+
+	if len(queriedIndices) == 0 {
+		return results, nil
+	}
+
+	query := fmt.Sprintf("query(%s) {\n%s}", buildVariableDecls(requests, queriedIndices), fields.String())
+
+	gqlReq := GraphQLRequest{Query: query, Variables: variables}
+	reqBody, err := json.Marshal(gqlReq)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrValidationFailed, fmt.Errorf("failed to marshal bulk GraphQL request: %w", err))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrValidationFailed, fmt.Errorf("failed to create HTTP request: %w", err))
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to make bulk GraphQL request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to read bulk response body: %w", err))
+	}
+
+	var gqlResp GraphQLResponse
+	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to unmarshal bulk response: %w", err))
+	}
+	if len(gqlResp.Errors) > 0 {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrSchemaNotFound, "GraphQL errors: %v", gqlResp.Errors)
+	}
+
+	for i, alias := range aliases {
+		value, ok := gqlResp.Data[alias]
+		hasPermission, _ := value.(bool)
+		if !ok {
+			results[i] = errorResponse(entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "missing result for alias %s", alias))
+			continue
+		}
+		results[i] = &pb.PermissionResponse{
+			HasPermission:  hasPermission,
+			Permissionship: c.mapPermissionship(hasPermission),
+			Implementation: pb.Implementation_IMPLEMENTATION_GRAPHQL,
+		}
+	}
+	return results, nil
+}
+
+// buildAliasedField renders one request as "aN: fieldName(args...)" plus the
+// variables that field's arguments reference, namespaced by index so
+// identical permissions across requests don't collide on variable names.
+func (c *Client) buildAliasedField(alias string, index int, req *pb.PermissionRequest) (string, map[string]interface{}, *entitlementerrors.Error) {
+	accountVar := fmt.Sprintf("accountId%d", index)
+	vars := map[string]interface{}{accountVar: req.Resource}
+
+	switch req.Permission {
+	case "can_initiate_payment":
+		var kv []interface{}
+		if amtStr, ok := req.Context["amount"]; ok {
+			kv = append(kv, "amount", amtStr)
+		}
+		caveatCtx, err := c.caveats.Build("under_limit", kv...)
+		if err != nil {
+			return "", nil, err.(*entitlementerrors.Error)
+		}
+
+		amountVar := fmt.Sprintf("amount%d", index)
+		vars[amountVar] = caveatCtx.Fields["amount"].GetNumberValue()
+		return fmt.Sprintf("  %s: canInitiatePayment(accountId: $%s, amount: $%s)", alias, accountVar, amountVar), vars, nil
+	case "can_download_statement":
+		return fmt.Sprintf("  %s: canDownloadStatement(accountId: $%s)", alias, accountVar), vars, nil
+	case "can_view_transactions":
+		return fmt.Sprintf("  %s: canViewTransactions(accountId: $%s)", alias, accountVar), vars, nil
+	default:
+		return fmt.Sprintf("  %s: canAccess(accountId: $%s)", alias, accountVar), vars, nil
+	}
+}
+
+// buildVariableDecls renders the "$accountId0: ID!, $amount0: Float!, ..."
+// declarations for every queried request's variables, keyed by its original
+// index in requests so names match what buildAliasedField emitted.
+func buildVariableDecls(requests []*pb.PermissionRequest, queriedIndices []int) string {
+	var decls bytes.Buffer
+	for n, i := range queriedIndices {
+		if n > 0 {
+			decls.WriteString(", ")
+		}
+		fmt.Fprintf(&decls, "$accountId%d: ID!", i)
+		if requests[i].Permission == "can_initiate_payment" {
+			fmt.Fprintf(&decls, ", $amount%d: Float!", i)
+		}
+	}
+	return decls.String()
+}
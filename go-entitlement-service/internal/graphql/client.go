@@ -7,15 +7,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
+	"os"
 	"time"
 
+	"github.com/adityakumar/labs/go-entitlement-service/internal/caveat"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
 	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
 )
 
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	caveats    *caveat.Context
 }
 
 type GraphQLRequest struct {
@@ -32,20 +35,36 @@ type GraphQLError struct {
 	Message string `json:"message"`
 }
 
-func NewClient() *Client {
+// NewClient builds a GraphQL backend client and loads caveat parameter types
+// from the SpiceDB schema at schemaPath, so check-time caveat context (e.g.
+// can_initiate_payment's "amount") is type-checked before it's sent.
+func NewClient(schemaPath string) (*Client, error) {
+	schemaBytes, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caveat schema from %s: %w", schemaPath, err)
+	}
+	caveats, err := caveat.Load(string(schemaBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load caveat schema: %w", err)
+	}
+
 	return &Client{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 		baseURL: "http://localhost:4000",
-	}
+		caveats: caveats,
+	}, nil
 }
 
 func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
 	start := time.Now()
 
 	// Map the gRPC request to GraphQL query
-	query, variables := c.buildGraphQLQuery(req)
+	query, variables, buildErr := c.buildGraphQLQuery(req)
+	if buildErr != nil {
+		return errorResponse(buildErr), nil
+	}
 
 	// Create GraphQL request
 	gqlReq := GraphQLRequest{
@@ -56,19 +75,13 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 	// Serialize request
 	reqBody, err := json.Marshal(gqlReq)
 	if err != nil {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("failed to marshal GraphQL request: %v", err),
-		}, nil
+		return errorResponse(entitlementerrors.New(entitlementerrors.ErrValidationFailed, fmt.Errorf("failed to marshal GraphQL request: %w", err))), nil
 	}
 
 	// Create HTTP request
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("failed to create HTTP request: %v", err),
-		}, nil
+		return errorResponse(entitlementerrors.New(entitlementerrors.ErrValidationFailed, fmt.Errorf("failed to create HTTP request: %w", err))), nil
 	}
 
 	// Set headers
@@ -78,37 +91,25 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 	// Make request
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("failed to make GraphQL request: %v", err),
-		}, nil
+		return errorResponse(entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to make GraphQL request: %w", err))), nil
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("failed to read response body: %v", err),
-		}, nil
+		return errorResponse(entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to read response body: %w", err))), nil
 	}
 
 	// Parse GraphQL response
 	var gqlResp GraphQLResponse
 	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("failed to unmarshal GraphQL response: %v", err),
-		}, nil
+		return errorResponse(entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, fmt.Errorf("failed to unmarshal GraphQL response: %w", err))), nil
 	}
 
 	// Check for GraphQL errors
 	if len(gqlResp.Errors) > 0 {
-		return &pb.PermissionResponse{
-			HasPermission: false,
-			ErrorMessage:  fmt.Sprintf("GraphQL errors: %v", gqlResp.Errors),
-		}, nil
+		return errorResponse(entitlementerrors.Newf(entitlementerrors.ErrSchemaNotFound, "GraphQL errors: %v", gqlResp.Errors)), nil
 	}
 
 	// Extract result from response
@@ -125,7 +126,7 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 	}, nil
 }
 
-func (c *Client) buildGraphQLQuery(req *pb.PermissionRequest) (string, map[string]interface{}) {
+func (c *Client) buildGraphQLQuery(req *pb.PermissionRequest) (string, map[string]interface{}, *entitlementerrors.Error) {
 	variables := map[string]interface{}{
 		"accountId": req.Resource,
 	}
@@ -136,38 +137,40 @@ func (c *Client) buildGraphQLQuery(req *pb.PermissionRequest) (string, map[strin
 		query($accountId: ID!) {
 			canViewTransactions(accountId: $accountId)
 		}`
-		return query, variables
+		return query, variables, nil
 
 	case "can_download_statement":
 		query := `
 		query($accountId: ID!) {
 			canDownloadStatement(accountId: $accountId)
 		}`
-		return query, variables
+		return query, variables, nil
 
 	case "can_initiate_payment":
-		// Extract amount from context if available
-		amount := 1000.0 // default
+		// under_limit's sole declared parameter is "amount"; a missing or
+		// malformed value is rejected here instead of silently defaulting.
+		var kv []interface{}
 		if amtStr, ok := req.Context["amount"]; ok {
-			// Try to parse as float64 directly
-			if amt, err := strconv.ParseFloat(amtStr, 64); err == nil {
-				amount = amt
-			}
+			kv = append(kv, "amount", amtStr)
+		}
+		caveatCtx, err := c.caveats.Build("under_limit", kv...)
+		if err != nil {
+			return "", nil, err.(*entitlementerrors.Error)
 		}
-		variables["amount"] = amount
+		variables["amount"] = caveatCtx.Fields["amount"].GetNumberValue()
 
 		query := `
 		query($accountId: ID!, $amount: Float!) {
 			canInitiatePayment(accountId: $accountId, amount: $amount)
 		}`
-		return query, variables
+		return query, variables, nil
 
 	case "can_access":
 		query := `
 		query($accountId: ID!) {
 			canAccess(accountId: $accountId)
 		}`
-		return query, variables
+		return query, variables, nil
 
 	default:
 		// Default to access check for unknown permissions
@@ -175,7 +178,7 @@ func (c *Client) buildGraphQLQuery(req *pb.PermissionRequest) (string, map[strin
 		query($accountId: ID!) {
 			canAccess(accountId: $accountId)
 		}`
-		return query, variables
+		return query, variables, nil
 	}
 }
 
@@ -195,3 +198,36 @@ func (c *Client) mapPermissionship(hasPermission bool) int32 {
 	}
 	return 1 // PERMISSIONSHIP_NO_PERMISSION
 }
+
+// errorResponse builds a PermissionResponse carrying err's taxonomy code and
+// message so cross-backend callers (e.g. IMPLEMENTATION_BOTH) can classify
+// the failure without parsing ErrorMessage.
+func errorResponse(err *entitlementerrors.Error) *pb.PermissionResponse {
+	return &pb.PermissionResponse{
+		HasPermission:  false,
+		Implementation: pb.Implementation_IMPLEMENTATION_GRAPHQL,
+		ErrorMessage:   err.Error(),
+		ErrorCode:      toProtoErrorCode(err.Code),
+	}
+}
+
+func toProtoErrorCode(code entitlementerrors.Code) pb.ErrorCode {
+	switch code {
+	case entitlementerrors.ErrValidationFailed:
+		return pb.ErrorCode_ERROR_CODE_VALIDATION_FAILED
+	case entitlementerrors.ErrBackendUnavailable:
+		return pb.ErrorCode_ERROR_CODE_BACKEND_UNAVAILABLE
+	case entitlementerrors.ErrSchemaNotFound:
+		return pb.ErrorCode_ERROR_CODE_SCHEMA_NOT_FOUND
+	case entitlementerrors.ErrDeadlineExceeded:
+		return pb.ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED
+	case entitlementerrors.ErrCaveatContextInvalid:
+		return pb.ErrorCode_ERROR_CODE_CAVEAT_CONTEXT_INVALID
+	case entitlementerrors.ErrUnauthenticated:
+		return pb.ErrorCode_ERROR_CODE_UNAUTHENTICATED
+	case entitlementerrors.ErrConflict:
+		return pb.ErrorCode_ERROR_CODE_CONFLICT
+	default:
+		return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+	}
+}
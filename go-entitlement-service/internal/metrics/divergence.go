@@ -0,0 +1,67 @@
+// Package metrics records cross-backend divergence observed while routing a
+// permission check to more than one backend (shadow, quorum, or the legacy
+// IMPLEMENTATION_BOTH comparison), so it can be tracked as a correctness
+// signal during a backend migration rather than only logged ad hoc.
+package metrics
+
+import (
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DivergenceSink receives one record per cross-backend comparison. Backends
+// that were skipped (e.g. unavailable) should not be included in results.
+type DivergenceSink interface {
+	RecordDivergence(actor, resource, permission string, results map[string]bool)
+}
+
+// divergenceChecks counts every cross-backend comparison, labeled by whether
+// the backends agreed, so dashboards can chart a divergence rate over time.
+var divergenceChecks = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "entitlement_backend_divergence_checks_total",
+		Help: "Cross-backend permission comparisons, labeled by agreement.",
+	},
+	[]string{"permission", "agreement"},
+)
+
+func init() {
+	prometheus.MustRegister(divergenceChecks)
+}
+
+// PrometheusSink increments divergenceChecks and logs a structured line for
+// any comparison where results disagree, so operators can find a specific
+// (actor, resource, permission) without scraping metrics first.
+type PrometheusSink struct{}
+
+// NewPrometheusSink returns the default production sink.
+func NewPrometheusSink() *PrometheusSink {
+	return &PrometheusSink{}
+}
+
+func (s *PrometheusSink) RecordDivergence(actor, resource, permission string, results map[string]bool) {
+	if agrees(results) {
+		divergenceChecks.WithLabelValues(permission, "agree").Inc()
+		return
+	}
+
+	divergenceChecks.WithLabelValues(permission, "disagree").Inc()
+	log.Printf("backend divergence: actor=%s resource=%s permission=%s results=%v", actor, resource, permission, results)
+}
+
+func agrees(results map[string]bool) bool {
+	seen := false
+	var first bool
+	for _, v := range results {
+		if !seen {
+			first = v
+			seen = true
+			continue
+		}
+		if v != first {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+package neo4j
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// CheckBulkPermissions groups requests by permission kind (each kind maps to
+// a fixed Cypher query in getPermissionQuery) and issues one UNWIND query per
+// group instead of one round-trip per request.
+func (c *Client) CheckBulkPermissions(ctx context.Context, requests []*pb.PermissionRequest) ([]*pb.PermissionResponse, error) {
+	results := make([]*pb.PermissionResponse, len(requests))
+
+	groups := make(map[string][]int)
+	for i, req := range requests {
+		groups[req.Permission] = append(groups[req.Permission], i)
+	}
+
+	session := c.session(ctx, nil)
+	defer session.Close(ctx)
+
+	for permission, indices := range groups {
+		query, err := c.getUnwindPermissionQuery(permission)
+		if err != nil {
+			typedErr := entitlementerrors.New(entitlementerrors.ErrSchemaNotFound, err)
+			for _, idx := range indices {
+				results[idx] = errorResponse(typedErr, 0)
+			}
+			continue
+		}
+
+		// rows[j]'s rowIdx is its position within this group's $requests
+		// list, echoed back by the query so the result can be rejoined to
+		// the right request even though two requests can share an
+		// actor/resource while differing in amount or test_date (e.g. a
+		// page of transactions against one account) - (userId, accountId)
+		// alone isn't a safe join key.
+		rows := make([]interface{}, len(indices))
+		for j, idx := range indices {
+			rows[j] = rowParams(j, requests[idx])
+		}
+
+		records, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) ([]*neo4j.Record, error) {
+			result, err := tx.Run(ctx, query, map[string]interface{}{"requests": rows})
+			if err != nil {
+				return nil, err
+			}
+			return result.Collect(ctx)
+		})
+		if err != nil {
+			typedErr := entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+			for _, idx := range indices {
+				results[idx] = errorResponse(typedErr, 0)
+			}
+			continue
+		}
+
+		seen := make([]bool, len(indices))
+		for _, record := range records {
+			rowIdx, ok := asInt(record)
+			if !ok || rowIdx < 0 || rowIdx >= len(indices) {
+				// A row whose echoed index doesn't map back into this
+				// group - ignore rather than guess which request it belongs
+				// to.
+				continue
+			}
+			idx := indices[rowIdx]
+			seen[rowIdx] = true
+
+			value, ok := record.Get("result")
+			if !ok {
+				results[idx] = errorResponse(entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "query result does not contain 'result' field"), 0)
+				continue
+			}
+
+			var hasPermission bool
+			switch v := value.(type) {
+			case bool:
+				hasPermission = v
+			case int64:
+				hasPermission = v != 0
+			case float64:
+				hasPermission = v != 0
+			default:
+				results[idx] = errorResponse(entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "unexpected result type: %T", value), 0)
+				continue
+			}
+
+			permissionship := pb.Permissionship_PERMISSIONSHIP_NO_PERMISSION
+			if hasPermission {
+				permissionship = pb.Permissionship_PERMISSIONSHIP_HAS_PERMISSION
+			}
+			results[idx] = &pb.PermissionResponse{
+				HasPermission:  hasPermission,
+				Permissionship: int32(permissionship),
+				Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
+				CheckedAt:      bookmarksToken(session.LastBookmarks()),
+			}
+		}
+
+		for rowIdx, idx := range indices {
+			if seen[rowIdx] {
+				continue
+			}
+			results[idx] = errorResponse(entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "missing bulk result row"), 0)
+		}
+	}
+
+	return results, nil
+}
+
+// asInt extracts the "rowIdx" column getUnwindPermissionQuery echoes back,
+// converting from whichever numeric type the driver handed back the
+// originally-int64 parameter as.
+func asInt(record *neo4j.Record) (int, bool) {
+	v, ok := record.Get("rowIdx")
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+func rowParams(rowIdx int, req *pb.PermissionRequest) map[string]interface{} {
+	testDate := time.Now().Format("2006-01-02T15:04:05")
+	if dateStr, ok := req.Context["test_date"]; ok {
+		testDate = dateStr
+	}
+	row := map[string]interface{}{
+		"rowIdx":    int64(rowIdx),
+		"userId":    req.Actor,
+		"accountId": req.Resource,
+		"now":       testDate,
+	}
+	if req.Permission == "can_initiate_payment" || req.Permission == "write" {
+		amount := 1000.0
+		if amtStr, ok := req.Context["amount"]; ok {
+			if amt, err := strconv.ParseFloat(amtStr, 64); err == nil {
+				amount = amt
+			}
+		}
+		row["amount"] = amount
+	}
+	return row
+}
+
+// errorResponse builds a PermissionResponse carrying err's taxonomy code.
+func errorResponse(err *entitlementerrors.Error, responseTimeMs float64) *pb.PermissionResponse {
+	return &pb.PermissionResponse{
+		HasPermission:  false,
+		Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
+		ErrorMessage:   err.Error(),
+		ErrorCode:      toProtoErrorCode(err.Code),
+		ResponseTimeMs: responseTimeMs,
+	}
+}
+
+func toProtoErrorCode(code entitlementerrors.Code) pb.ErrorCode {
+	switch code {
+	case entitlementerrors.ErrValidationFailed:
+		return pb.ErrorCode_ERROR_CODE_VALIDATION_FAILED
+	case entitlementerrors.ErrBackendUnavailable:
+		return pb.ErrorCode_ERROR_CODE_BACKEND_UNAVAILABLE
+	case entitlementerrors.ErrSchemaNotFound:
+		return pb.ErrorCode_ERROR_CODE_SCHEMA_NOT_FOUND
+	case entitlementerrors.ErrDeadlineExceeded:
+		return pb.ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED
+	case entitlementerrors.ErrCaveatContextInvalid:
+		return pb.ErrorCode_ERROR_CODE_CAVEAT_CONTEXT_INVALID
+	case entitlementerrors.ErrUnauthenticated:
+		return pb.ErrorCode_ERROR_CODE_UNAUTHENTICATED
+	case entitlementerrors.ErrConflict:
+		return pb.ErrorCode_ERROR_CODE_CONFLICT
+	default:
+		return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+	}
+}
+
+// getUnwindPermissionQuery adapts the single-request Cypher for permission to
+// read its parameters from an UNWIND row instead of bound query parameters,
+// so one statement answers the whole $requests batch. The single-request
+// query projects its row away at the first "WITH user, account" (it has no
+// need to carry $userId/$accountId's row any further once they're bound), so
+// r is threaded through every WITH here to stay in scope for the later
+// r.now/r.amount references, and r.rowIdx is echoed back in the final RETURN
+// so CheckBulkPermissions can re-join each row to its request by that index
+// rather than by position or by (userId, accountId) - a CALL subquery can
+// regroup or drop rows, and two requests can share an actor/resource while
+// differing in amount or test_date (e.g. a page of transactions against one
+// account), so neither position nor (userId, accountId) alone is a safe key.
+func (c *Client) getUnwindPermissionQuery(permission string) (string, error) {
+	single, err := c.getPermissionQuery(permission)
+	if err != nil {
+		return "", err
+	}
+	perRow := strings.NewReplacer(
+		"$userId", "r.userId",
+		"$accountId", "r.accountId",
+		"$now", "r.now",
+		"$amount", "r.amount",
+	).Replace(single)
+	perRow = strings.ReplaceAll(perRow, "WITH ", "WITH r, ")
+	perRow = strings.Replace(perRow, "as result\n", "as result, r.rowIdx as rowIdx\n", 1)
+	return "UNWIND $requests AS r\n" + perRow, nil
+}
@@ -4,41 +4,127 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
 	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
+// Config holds the pool/session knobs NewClient exposes beyond the bare
+// connection string - zero values fall back to the driver's own defaults.
+type Config struct {
+	// MaxConnectionPoolSize bounds how many connections the driver keeps
+	// open per remote, reused across calls. Sessions are not pooled: each
+	// call opens its own session (see session) over those connections.
+	MaxConnectionPoolSize int
+	// ConnectionAcquisitionTimeout bounds how long a call waits for a pooled
+	// connection before failing, rather than queuing indefinitely.
+	ConnectionAcquisitionTimeout time.Duration
+	// Database names the database a session runs against; "" uses the
+	// driver's default database.
+	Database string
+	// MaxDeptDepth caps the CHILD_OF* variable-length traversal
+	// getPermissionQuery uses to walk department inheritance, so a cyclic or
+	// deep org chart can't turn a permission check into a runaway query.
+	// <= 0 falls back to defaultMaxDeptDepth.
+	MaxDeptDepth int
+	// TokenCacheCapacity bounds how many users' AccessTokens CheckPermission's
+	// bitmap fast path keeps resident at once. <= 0 falls back to
+	// defaultTokenCacheCapacity; a negative value already caught by that
+	// fallback isn't a valid way to disable the cache - use DisableTokenCache.
+	TokenCacheCapacity int
+	// TokenCacheTTL bounds how long an AccessToken is trusted without a
+	// write-driven invalidation. <= 0 falls back to defaultTokenTTL.
+	TokenCacheTTL time.Duration
+	// DisableTokenCache turns CheckPermission back into a pure per-call
+	// Cypher round-trip, for callers that would rather not reason about
+	// cache staleness (tests asserting on exact query counts, a backend
+	// fronted by something that already caches at a different layer).
+	DisableTokenCache bool
+}
+
+// defaultMaxDeptDepth is the department-inheritance traversal bound used
+// when Config.MaxDeptDepth isn't set.
+const defaultMaxDeptDepth = 5
+
 type Client struct {
-	driver neo4j.Driver
+	driver       neo4j.DriverWithContext
+	database     string
+	maxDeptDepth int
+	tokens       *tokenCache
 }
 
-func NewClient(uri, username, password string) (*Client, error) {
-	driver, err := neo4j.NewDriver(uri, neo4j.BasicAuth(username, password, ""))
+func NewClient(uri, username, password string, cfg Config) (*Client, error) {
+	driver, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(username, password, ""), func(c *neo4j.Config) {
+		if cfg.MaxConnectionPoolSize > 0 {
+			c.MaxConnectionPoolSize = cfg.MaxConnectionPoolSize
+		}
+		if cfg.ConnectionAcquisitionTimeout > 0 {
+			c.ConnectionAcquisitionTimeout = cfg.ConnectionAcquisitionTimeout
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
 	}
 
-	// Test the connection
-	if err := driver.VerifyConnectivity(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := driver.VerifyConnectivity(ctx); err != nil {
 		return nil, fmt.Errorf("failed to connect to Neo4j: %w", err)
 	}
 
-	return &Client{driver: driver}, nil
+	maxDeptDepth := cfg.MaxDeptDepth
+	if maxDeptDepth <= 0 {
+		maxDeptDepth = defaultMaxDeptDepth
+	}
+
+	client := &Client{driver: driver, database: cfg.Database, maxDeptDepth: maxDeptDepth}
+
+	if !cfg.DisableTokenCache {
+		capacity := cfg.TokenCacheCapacity
+		if capacity <= 0 {
+			capacity = defaultTokenCacheCapacity
+		}
+		ttl := cfg.TokenCacheTTL
+		if ttl <= 0 {
+			ttl = defaultTokenTTL
+		}
+		client.tokens = newTokenCache(capacity, ttl)
+	}
+
+	return client, nil
 }
 
 func (c *Client) Close() error {
-	return c.driver.Close()
+	return c.driver.Close(context.Background())
+}
+
+// session opens a pooled SessionWithContext honoring req's consistency
+// requirement. An at_least_as_fresh requirement is passed as a bookmark:
+// Neo4j waits for the transaction behind it to become visible before
+// running ours, the same tradeoff SpiceDB's AtLeastAsFresh ZedToken
+// expresses.
+func (c *Client) session(ctx context.Context, consistency *pb.Consistency) neo4j.SessionWithContext {
+	cfg := neo4j.SessionConfig{DatabaseName: c.database}
+	if token := atLeastAsFreshToken(consistency); token != "" {
+		cfg.Bookmarks = neo4j.BookmarksFromRawValues(token)
+	}
+	return c.driver.NewSession(ctx, cfg)
 }
 
 func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
 	startTime := time.Now()
 
+	if resp, handled, err := c.checkPermissionFromToken(ctx, req, startTime); handled {
+		return resp, err
+	}
+
 	// Map permission names to Cypher queries
 	query, err := c.getPermissionQuery(req.Permission)
 	if err != nil {
-		return nil, err
+		return nil, entitlementerrors.New(entitlementerrors.ErrSchemaNotFound, err)
 	}
 
 	// Prepare parameters
@@ -65,31 +151,36 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 		params["amount"] = amount
 	}
 
-	// Execute query
-	session := c.driver.NewSession(neo4j.SessionConfig{})
-	defer session.Close()
-
-	result, err := session.Run(query, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute Neo4j query: %w", err)
+	explain := req.Context["explain"] == "true"
+	if explain {
+		query, err = c.getExplainPermissionQuery(req.Permission)
+		if err != nil {
+			return nil, entitlementerrors.New(entitlementerrors.ErrSchemaNotFound, err)
+		}
 	}
 
-	// Get the first record
-	record, err := result.Single()
+	session := c.session(ctx, req.Consistency)
+	defer session.Close(ctx)
+
+	record, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) (*neo4j.Record, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Single(ctx)
+	})
 	if err != nil {
-		responseTime := time.Since(startTime).Milliseconds()
-		return &pb.PermissionResponse{
-			HasPermission:  false,
-			Permissionship: int32(pb.Permissionship_PERMISSIONSHIP_NO_PERMISSION),
-			Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
-			ResponseTimeMs: float64(responseTime),
-		}, nil
+		// A query failure is not the same thing as "no permission" - the
+		// caller (e.g. Service.checkSingle's cache.GetOrLoad) must not treat
+		// this as a cacheable success, so return a real error instead of a
+		// false-denial response.
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
 	}
 
 	// Extract the permission result
 	hasPermission, ok := record.Get("result")
 	if !ok {
-		return nil, fmt.Errorf("query result does not contain 'result' field")
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "query result does not contain 'result' field")
 	}
 
 	// Convert to boolean
@@ -102,7 +193,7 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 	case float64:
 		permission = v != 0
 	default:
-		return nil, fmt.Errorf("unexpected result type: %T", hasPermission)
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "unexpected result type: %T", hasPermission)
 	}
 
 	// Map to permissionship
@@ -115,18 +206,264 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 
 	responseTime := time.Since(startTime).Milliseconds()
 
+	var grants []*pb.GrantEdge
+	if explain {
+		grants = grantEdgesFromRecord(record)
+	}
+
 	return &pb.PermissionResponse{
 		HasPermission:  permission,
 		Permissionship: int32(permissionship),
 		Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
 		ResponseTimeMs: float64(responseTime),
+		CheckedAt:      bookmarksToken(session.LastBookmarks()),
+		Grants:         grants,
 	}, nil
 }
 
+// grantEdgesFromRecord reads the direct_owner/poa_edges/role_edges/
+// accountant_edges columns getExplainPermissionQuery adds and flattens them
+// into the GrantEdge list PermissionResponse.grants carries. A column
+// missing from record (a permission whose explain query doesn't produce it,
+// e.g. can_access has no poa_edges) is simply skipped.
+func grantEdgesFromRecord(record *neo4j.Record) []*pb.GrantEdge {
+	edges := make([]*pb.GrantEdge, 0)
+
+	if directOwner, ok := record.Get("direct_owner"); ok {
+		if v, _ := directOwner.(bool); v {
+			edges = append(edges, &pb.GrantEdge{Kind: "direct_owner"})
+		}
+	}
+
+	if poaEdges, ok := record.Get("poa_edges"); ok {
+		for _, raw := range asSliceOfMaps(poaEdges) {
+			// An OPTIONAL MATCH with no valid edge still collects one
+			// all-null map; a matched edge always has starts_at set.
+			if raw["starts_at"] == nil {
+				continue
+			}
+			edges = append(edges, &pb.GrantEdge{
+				Kind: "poa",
+				Detail: fmt.Sprintf("starts_at=%v,expires_at=%v,payment_limit=%v",
+					raw["starts_at"], raw["expires_at"], raw["payment_limit"]),
+			})
+		}
+	}
+
+	if roleEdges, ok := record.Get("role_edges"); ok {
+		for _, raw := range asSliceOfMaps(roleEdges) {
+			if raw["role_id"] == nil {
+				continue
+			}
+			edges = append(edges, &pb.GrantEdge{
+				Kind:   "role",
+				Detail: fmt.Sprintf("role:%v@org:%v", raw["role_id"], raw["org_id"]),
+			})
+		}
+	}
+
+	if accountantEdges, ok := record.Get("accountant_edges"); ok {
+		for _, raw := range asSliceOfMaps(accountantEdges) {
+			if raw["starts_at"] == nil {
+				continue
+			}
+			edges = append(edges, &pb.GrantEdge{
+				Kind:   "accountant_access",
+				Detail: fmt.Sprintf("starts_at=%v,expires_at=%v", raw["starts_at"], raw["expires_at"]),
+			})
+		}
+	}
+
+	return edges
+}
+
+// asSliceOfMaps converts a Cypher collect({...}) column - []interface{} of
+// map[string]interface{} over the wire - into a directly rangeable slice,
+// tolerating nil/wrongly-typed columns instead of panicking.
+func asSliceOfMaps(value interface{}) []map[string]interface{} {
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	maps := make([]map[string]interface{}, 0, len(raw))
+	for _, item := range raw {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// atLeastAsFreshToken extracts the at_least_as_fresh bookmark from a
+// Consistency requirement, if that's the mode requested.
+func atLeastAsFreshToken(c *pb.Consistency) string {
+	if req, ok := c.GetRequirement().(*pb.Consistency_AtLeastAsFresh); ok {
+		return req.AtLeastAsFresh
+	}
+	return ""
+}
+
+// bookmarksToken flattens a session's bookmarks into the single opaque
+// string PermissionResponse.CheckedAt carries, usable as a later request's
+// at_least_as_fresh value.
+func bookmarksToken(bookmarks neo4j.Bookmarks) string {
+	return strings.Join(bookmarks, ",")
+}
+
+// roleAccessSubquery builds the CALL subquery that counts every role
+// granting access to (user, account) through any of the three paths a role
+// can reach an org: a direct HAS_ROLE, an inherited department role
+// (BELONG_TO -> CHILD_OF*0..maxDeptDepth -> ALLOW_INHERIT), or a role
+// attached to the user's own department with inheritance explicitly turned
+// off (BELONG_TO -> ALLOW_NO_INHERIT). The UNION is nested inside its own
+// CALL so the count() lives in the outer CALL's RETURN: an aggregation with
+// no grouping key always yields exactly one row, even when the inner UNION
+// matches nothing, so the enclosing (user, account) row is never dropped.
+// The caller picks role_access straight off the outer CALL's scope.
+func (c *Client) roleAccessSubquery() string {
+	return fmt.Sprintf(`
+		CALL {
+			WITH user, account
+			CALL {
+				WITH user, account
+				MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account)
+				RETURN role as granting_role
+				UNION
+				WITH user, account
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:CHILD_OF*0..%d]->(:Dept)-[:ALLOW_INHERIT]->(role:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account)
+				RETURN role as granting_role
+				UNION
+				WITH user, account
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:ALLOW_NO_INHERIT]->(role:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account)
+				RETURN role as granting_role
+			}
+			RETURN count(granting_role) as role_access
+		}
+	`, c.maxDeptDepth)
+}
+
+// roleAccessExplainSubquery is roleAccessSubquery's explain-mode twin: it
+// surfaces the matched role and org ids via collect() alongside the count,
+// so getExplainPermissionQuery can turn them into GrantEdge details. Same
+// nested-CALL shape as roleAccessSubquery, and for the same reason: the
+// aggregation has to sit in the outer CALL's RETURN so a user with no
+// matching role still yields one row with role_access = 0 and
+// role_edges = [], instead of dropping the (user, account) row entirely.
+func (c *Client) roleAccessExplainSubquery() string {
+	return fmt.Sprintf(`
+		CALL {
+			WITH user, account
+			CALL {
+				WITH user, account
+				MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
+				RETURN role.id as role_id, org.id as org_id
+				UNION
+				WITH user, account
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:CHILD_OF*0..%d]->(:Dept)-[:ALLOW_INHERIT]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
+				RETURN role.id as role_id, org.id as org_id
+				UNION
+				WITH user, account
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:ALLOW_NO_INHERIT]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
+				RETURN role.id as role_id, org.id as org_id
+			}
+			RETURN count(role_id) as role_access, collect({role_id: role_id, org_id: org_id}) as role_edges
+		}
+	`, c.maxDeptDepth)
+}
+
+// getExplainPermissionQuery is getPermissionQuery's explain-mode twin,
+// called only when the caller sets context["explain"] = "true" so the
+// default CheckPermission/CheckBulkPermissions path never pays for the
+// extra collect()s. Instead of folding each access path straight into a
+// count, it also collects the matched edges' properties so
+// grantEdgesFromRecord can turn them into GrantEdge entries.
+func (c *Client) getExplainPermissionQuery(permission string) (string, error) {
+	roleAccess := c.roleAccessExplainSubquery()
+
+	switch permission {
+	case "read", "can_view_transactions":
+		return fmt.Sprintf(`
+			OPTIONAL MATCH (user:User {id: $userId})
+			OPTIONAL MATCH (account:Account {id: $accountId})
+			WITH user, account
+			OPTIONAL MATCH (user)-[owns:OWNS]->(account)
+			WITH user, account, owns IS NOT NULL as direct_owner
+			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
+			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at)
+			WITH user, account, direct_owner, count(poa) as valid_poa,
+				collect({starts_at: poa.starts_at, expires_at: poa.expires_at, payment_limit: poa.payment_limit}) as poa_edges
+			%s
+			WITH user, account, direct_owner, valid_poa, poa_edges, role_access, role_edges
+			OPTIONAL MATCH (user)-[acc:HAS_ACCOUNTANT_ACCESS]->(account)
+			WHERE datetime($now) >= datetime(acc.starts_at) AND datetime($now) <= datetime(acc.expires_at)
+			WITH user, account, direct_owner, valid_poa, poa_edges, role_access, role_edges, count(acc) as accountant_access,
+				collect({starts_at: acc.starts_at, expires_at: acc.expires_at}) as accountant_edges
+			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0 OR accountant_access > 0) as result,
+				direct_owner, poa_edges, role_edges, accountant_edges
+		`, roleAccess), nil
+
+	case "write", "can_initiate_payment":
+		return fmt.Sprintf(`
+			OPTIONAL MATCH (user:User {id: $userId})
+			OPTIONAL MATCH (account:Account {id: $accountId})
+			WITH user, account
+			OPTIONAL MATCH (user)-[owns:OWNS]->(account)
+			WITH user, account, owns IS NOT NULL as direct_owner
+			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
+			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at) AND ($amount <= poa.payment_limit OR poa.payment_limit IS NULL)
+			WITH user, account, direct_owner, count(poa) as valid_poa,
+				collect({starts_at: poa.starts_at, expires_at: poa.expires_at, payment_limit: poa.payment_limit}) as poa_edges
+			%s
+			WITH user, account, direct_owner, valid_poa, poa_edges, role_access, role_edges
+			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0) as result,
+				direct_owner, poa_edges, role_edges
+		`, roleAccess), nil
+
+	case "can_download_statement":
+		return fmt.Sprintf(`
+			OPTIONAL MATCH (user:User {id: $userId})
+			OPTIONAL MATCH (account:Account {id: $accountId})
+			WITH user, account
+			OPTIONAL MATCH (user)-[owns:OWNS]->(account)
+			WITH user, account, owns IS NOT NULL as direct_owner
+			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
+			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at)
+			WITH user, account, direct_owner, count(poa) as valid_poa,
+				collect({starts_at: poa.starts_at, expires_at: poa.expires_at, payment_limit: poa.payment_limit}) as poa_edges
+			%s
+			WITH user, account, direct_owner, valid_poa, poa_edges, role_access, role_edges
+			OPTIONAL MATCH (user)-[acc:HAS_ACCOUNTANT_ACCESS]->(account)
+			WHERE datetime($now) >= datetime(acc.starts_at) AND datetime($now) <= datetime(acc.expires_at)
+			WITH user, account, direct_owner, valid_poa, poa_edges, role_access, role_edges, count(acc) as accountant_access,
+				collect({starts_at: acc.starts_at, expires_at: acc.expires_at}) as accountant_edges
+			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0 OR accountant_access > 0) as result,
+				direct_owner, poa_edges, role_edges, accountant_edges
+		`, roleAccess), nil
+
+	case "can_access":
+		return fmt.Sprintf(`
+			OPTIONAL MATCH (user:User {id: $userId})
+			OPTIONAL MATCH (account:Account {id: $accountId})
+			WITH user, account
+			OPTIONAL MATCH (user)-[owns:OWNS]->(account)
+			WITH user, account, owns IS NOT NULL as direct_owner
+			%s
+			WITH user, account, direct_owner, role_access, role_edges
+			RETURN (direct_owner OR role_access > 0) as result,
+				direct_owner, role_edges
+		`, roleAccess), nil
+
+	default:
+		return "", fmt.Errorf("unsupported permission: %s", permission)
+	}
+}
+
 func (c *Client) getPermissionQuery(permission string) (string, error) {
+	roleAccess := c.roleAccessSubquery()
+
 	switch permission {
 	case "read", "can_view_transactions":
-		return `
+		return fmt.Sprintf(`
 			OPTIONAL MATCH (user:User {id: $userId})
 			OPTIONAL MATCH (account:Account {id: $accountId})
 			WITH user, account
@@ -135,16 +472,16 @@ func (c *Client) getPermissionQuery(permission string) (string, error) {
 			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
 			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at)
 			WITH user, account, direct_owner, count(poa) as valid_poa
-			OPTIONAL MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
-			WITH user, account, direct_owner, valid_poa, count(role) as role_access
+			%s
+			WITH user, account, direct_owner, valid_poa, role_access
 			OPTIONAL MATCH (user)-[acc:HAS_ACCOUNTANT_ACCESS]->(account)
 			WHERE datetime($now) >= datetime(acc.starts_at) AND datetime($now) <= datetime(acc.expires_at)
 			WITH user, account, direct_owner, valid_poa, role_access, count(acc) as accountant_access
 			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0 OR accountant_access > 0) as result
-		`, nil
+		`, roleAccess), nil
 
 	case "write", "can_initiate_payment":
-		return `
+		return fmt.Sprintf(`
 			OPTIONAL MATCH (user:User {id: $userId})
 			OPTIONAL MATCH (account:Account {id: $accountId})
 			WITH user, account
@@ -153,13 +490,13 @@ func (c *Client) getPermissionQuery(permission string) (string, error) {
 			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
 			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at) AND ($amount <= poa.payment_limit OR poa.payment_limit IS NULL)
 			WITH user, account, direct_owner, count(poa) as valid_poa
-			OPTIONAL MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
-			WITH user, account, direct_owner, valid_poa, count(role) as role_access
+			%s
+			WITH user, account, direct_owner, valid_poa, role_access
 			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0) as result
-		`, nil
+		`, roleAccess), nil
 
 	case "can_download_statement":
-		return `
+		return fmt.Sprintf(`
 			OPTIONAL MATCH (user:User {id: $userId})
 			OPTIONAL MATCH (account:Account {id: $accountId})
 			WITH user, account
@@ -168,25 +505,25 @@ func (c *Client) getPermissionQuery(permission string) (string, error) {
 			OPTIONAL MATCH (user)-[poa:HAS_POA]->(account)
 			WHERE datetime($now) >= datetime(poa.starts_at) AND datetime($now) <= datetime(poa.expires_at)
 			WITH user, account, direct_owner, count(poa) as valid_poa
-			OPTIONAL MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
-			WITH user, account, direct_owner, valid_poa, count(role) as role_access
+			%s
+			WITH user, account, direct_owner, valid_poa, role_access
 			OPTIONAL MATCH (user)-[acc:HAS_ACCOUNTANT_ACCESS]->(account)
 			WHERE datetime($now) >= datetime(acc.starts_at) AND datetime($now) <= datetime(acc.expires_at)
 			WITH user, account, direct_owner, valid_poa, role_access, count(acc) as accountant_access
 			RETURN (direct_owner OR valid_poa > 0 OR role_access > 0 OR accountant_access > 0) as result
-		`, nil
+		`, roleAccess), nil
 
 	case "can_access":
-		return `
+		return fmt.Sprintf(`
 			OPTIONAL MATCH (user:User {id: $userId})
 			OPTIONAL MATCH (account:Account {id: $accountId})
 			WITH user, account
 			OPTIONAL MATCH (user)-[owns:OWNS]->(account)
 			WITH user, account, owns IS NOT NULL as direct_owner
-			OPTIONAL MATCH (user)-[:HAS_ROLE]->(role:Role)-[:MEMBER_OF]->(org:Org)-[:OWNS]->(account)
-			WITH user, account, direct_owner, count(role) as role_access
+			%s
+			WITH user, account, direct_owner, role_access
 			RETURN (direct_owner OR role_access > 0) as result
-		`, nil
+		`, roleAccess), nil
 
 	default:
 		return "", fmt.Errorf("unsupported permission: %s", permission)
@@ -0,0 +1,43 @@
+package neo4j
+
+import "time"
+
+// User, Org, and Role are the domain structs the node repositories below
+// return. CheckPermission and the fixture loader never construct these
+// themselves - they read/write the graph directly - so these types only
+// matter to the admin/seeding callers this file exists for.
+type User struct {
+	ID          string
+	DisplayName string
+}
+
+type Org struct {
+	ID          string
+	DisplayName string
+}
+
+type Role struct {
+	ID          string
+	DisplayName string
+}
+
+// POAGrant mirrors a HAS_POA edge: UserID may initiate payments on
+// AccountID between StartsAt and ExpiresAt, capped at PaymentLimit. A nil
+// PaymentLimit means no cap, matching writeHasPOA's handling of a caveat
+// with no max_amount.
+type POAGrant struct {
+	UserID       string
+	AccountID    string
+	StartsAt     time.Time
+	ExpiresAt    time.Time
+	PaymentLimit *float64
+}
+
+// AccountantGrant mirrors a HAS_ACCOUNTANT_ACCESS edge: UserID may view,
+// but not move money on, AccountID between StartsAt and ExpiresAt.
+type AccountantGrant struct {
+	UserID    string
+	AccountID string
+	StartsAt  time.Time
+	ExpiresAt time.Time
+}
@@ -0,0 +1,255 @@
+package neo4j
+
+import (
+	"context"
+	"time"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// grantTimeLayout formats StartsAt/ExpiresAt the same way bulk.go's
+// rowParams and getPermissionQuery's datetime() comparisons expect.
+const grantTimeLayout = "2006-01-02T15:04:05"
+
+// PermissionGrantRepository manages the relationship edges getPermissionQuery
+// walks - HAS_POA, HAS_ACCOUNTANT_ACCESS, HAS_ROLE, MEMBER_OF - so admin APIs
+// and seeding tools can grant/revoke access without writing Cypher of their
+// own.
+type PermissionGrantRepository struct {
+	client *Client
+}
+
+func NewPermissionGrantRepository(client *Client) *PermissionGrantRepository {
+	return &PermissionGrantRepository{client: client}
+}
+
+// GrantPOA creates or replaces the HAS_POA edge from userID to accountID. A
+// nil paymentLimit means no cap.
+func (r *PermissionGrantRepository) GrantPOA(ctx context.Context, userID, accountID string, startsAt, expiresAt time.Time, paymentLimit *float64) (*POAGrant, error) {
+	if !expiresAt.After(startsAt) {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "expires_at (%s) must be after starts_at (%s)", expiresAt, startsAt)
+	}
+	if paymentLimit != nil && *paymentLimit < 0 {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "payment limit must be non-negative, got %v", *paymentLimit)
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	params := map[string]interface{}{
+		"userId":    userID,
+		"accountId": accountID,
+		"startsAt":  startsAt.Format(grantTimeLayout),
+		"expiresAt": expiresAt.Format(grantTimeLayout),
+	}
+	if paymentLimit != nil {
+		params["paymentLimit"] = *paymentLimit
+	} else {
+		params["paymentLimit"] = nil
+	}
+
+	if err := runWrite(ctx, session,
+		`MERGE (u:User {id: $userId})
+		 MERGE (a:Account {id: $accountId})
+		 MERGE (u)-[poa:HAS_POA]->(a)
+		 SET poa.starts_at = $startsAt, poa.expires_at = $expiresAt, poa.payment_limit = $paymentLimit`,
+		params); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	r.client.publishInvalidation(userID)
+	return &POAGrant{UserID: userID, AccountID: accountID, StartsAt: startsAt, ExpiresAt: expiresAt, PaymentLimit: paymentLimit}, nil
+}
+
+// RevokePOA deletes the HAS_POA edge from userID to accountID, if any.
+func (r *PermissionGrantRepository) RevokePOA(ctx context.Context, userID, accountID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if err := runWrite(ctx, session,
+		"MATCH (:User {id: $userId})-[poa:HAS_POA]->(:Account {id: $accountId}) DELETE poa",
+		map[string]interface{}{"userId": userID, "accountId": accountID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation(userID)
+	return nil
+}
+
+// GrantAccountantAccess creates or replaces the HAS_ACCOUNTANT_ACCESS edge
+// from userID to accountID.
+func (r *PermissionGrantRepository) GrantAccountantAccess(ctx context.Context, userID, accountID string, startsAt, expiresAt time.Time) (*AccountantGrant, error) {
+	if !expiresAt.After(startsAt) {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "expires_at (%s) must be after starts_at (%s)", expiresAt, startsAt)
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if err := runWrite(ctx, session,
+		`MERGE (u:User {id: $userId})
+		 MERGE (a:Account {id: $accountId})
+		 MERGE (u)-[acc:HAS_ACCOUNTANT_ACCESS]->(a)
+		 SET acc.starts_at = $startsAt, acc.expires_at = $expiresAt`,
+		map[string]interface{}{
+			"userId":    userID,
+			"accountId": accountID,
+			"startsAt":  startsAt.Format(grantTimeLayout),
+			"expiresAt": expiresAt.Format(grantTimeLayout),
+		}); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	r.client.publishInvalidation(userID)
+	return &AccountantGrant{UserID: userID, AccountID: accountID, StartsAt: startsAt, ExpiresAt: expiresAt}, nil
+}
+
+// RevokeAccountantAccess deletes the HAS_ACCOUNTANT_ACCESS edge from userID
+// to accountID, if any.
+func (r *PermissionGrantRepository) RevokeAccountantAccess(ctx context.Context, userID, accountID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if err := runWrite(ctx, session,
+		"MATCH (:User {id: $userId})-[acc:HAS_ACCOUNTANT_ACCESS]->(:Account {id: $accountId}) DELETE acc",
+		map[string]interface{}{"userId": userID, "accountId": accountID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation(userID)
+	return nil
+}
+
+// AssignRole creates the HAS_ROLE edge a role_access check walks from
+// userID to roleID. Both nodes must already exist.
+func (r *PermissionGrantRepository) AssignRole(ctx context.Context, userID, roleID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if _, err := getUserRecord(ctx, session, userID); err != nil {
+		return entitlementerrors.Newf(entitlementerrors.ErrNotFound, "user %q not found", userID)
+	}
+	if _, err := getRoleRecord(ctx, session, roleID); err != nil {
+		return entitlementerrors.Newf(entitlementerrors.ErrNotFound, "role %q not found", roleID)
+	}
+
+	if err := runWrite(ctx, session,
+		"MATCH (u:User {id: $userId}), (r:Role {id: $roleId}) MERGE (u)-[:HAS_ROLE]->(r)",
+		map[string]interface{}{"userId": userID, "roleId": roleID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation(userID)
+	return nil
+}
+
+// UnassignRole deletes the HAS_ROLE edge from userID to roleID, if any.
+func (r *PermissionGrantRepository) UnassignRole(ctx context.Context, userID, roleID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if err := runWrite(ctx, session,
+		"MATCH (:User {id: $userId})-[rel:HAS_ROLE]->(:Role {id: $roleId}) DELETE rel",
+		map[string]interface{}{"userId": userID, "roleId": roleID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation(userID)
+	return nil
+}
+
+// AddOrgMember creates the MEMBER_OF edge from roleID to orgID that scopes
+// which org's accounts a role's HAS_ROLE holders can reach.
+func (r *PermissionGrantRepository) AddOrgMember(ctx context.Context, roleID, orgID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if _, err := getRoleRecord(ctx, session, roleID); err != nil {
+		return entitlementerrors.Newf(entitlementerrors.ErrNotFound, "role %q not found", roleID)
+	}
+	if _, err := getOrgRecord(ctx, session, orgID); err != nil {
+		return entitlementerrors.Newf(entitlementerrors.ErrNotFound, "org %q not found", orgID)
+	}
+
+	if err := runWrite(ctx, session,
+		"MATCH (r:Role {id: $roleId}), (o:Org {id: $orgId}) MERGE (r)-[:MEMBER_OF]->(o)",
+		map[string]interface{}{"roleId": roleID, "orgId": orgID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	// Every user holding roleID just gained (or changed) reach into orgId's
+	// accounts, and nothing here tells us who those users are cheaply - so
+	// invalidate every cached AccessToken rather than miss one.
+	r.client.publishInvalidation("")
+	return nil
+}
+
+// RemoveOrgMember deletes the MEMBER_OF edge from roleID to orgID, if any.
+func (r *PermissionGrantRepository) RemoveOrgMember(ctx context.Context, roleID, orgID string) error {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if err := runWrite(ctx, session,
+		"MATCH (:Role {id: $roleId})-[rel:MEMBER_OF]->(:Org {id: $orgId}) DELETE rel",
+		map[string]interface{}{"roleId": roleID, "orgId": orgID}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation("")
+	return nil
+}
+
+// ListPOAGrants returns every HAS_POA edge pointing at accountID.
+func (r *PermissionGrantRepository) ListPOAGrants(ctx context.Context, accountID string) ([]*POAGrant, error) {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	records, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) ([]*neo4j.Record, error) {
+		result, err := tx.Run(ctx,
+			`MATCH (u:User)-[poa:HAS_POA]->(a:Account {id: $accountId})
+			 RETURN u.id as userId, a.id as accountId, poa.starts_at as startsAt, poa.expires_at as expiresAt, poa.payment_limit as paymentLimit
+			 ORDER BY u.id`,
+			map[string]interface{}{"accountId": accountID})
+		if err != nil {
+			return nil, err
+		}
+		return result.Collect(ctx)
+	})
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	grants := make([]*POAGrant, 0, len(records))
+	for _, record := range records {
+		grant, err := scanPOAGrant(record)
+		if err != nil {
+			return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func scanPOAGrant(record *neo4j.Record) (*POAGrant, error) {
+	grant := &POAGrant{}
+	if v, ok := record.Get("userId"); ok {
+		grant.UserID, _ = v.(string)
+	}
+	if v, ok := record.Get("accountId"); ok {
+		grant.AccountID, _ = v.(string)
+	}
+	if v, ok := record.Get("startsAt"); ok && v != nil {
+		startsAt, err := time.Parse(grantTimeLayout, v.(string))
+		if err != nil {
+			return nil, err
+		}
+		grant.StartsAt = startsAt
+	}
+	if v, ok := record.Get("expiresAt"); ok && v != nil {
+		expiresAt, err := time.Parse(grantTimeLayout, v.(string))
+		if err != nil {
+			return nil, err
+		}
+		grant.ExpiresAt = expiresAt
+	}
+	if v, ok := record.Get("paymentLimit"); ok && v != nil {
+		limit, _ := v.(float64)
+		grant.PaymentLimit = &limit
+	}
+	return grant, nil
+}
@@ -0,0 +1,125 @@
+package neo4j
+
+import (
+	"context"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// RoleRepository manages Role nodes, the -[:MEMBER_OF]-> Org grants that
+// HAS_ROLE/ALLOW_INHERIT/ALLOW_NO_INHERIT edges attach users to.
+type RoleRepository struct {
+	client *Client
+}
+
+func NewRoleRepository(client *Client) *RoleRepository {
+	return &RoleRepository{client: client}
+}
+
+func (r *RoleRepository) Create(ctx context.Context, id, displayName string) (*Role, error) {
+	if id == "" {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "role id is required")
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if _, err := getRoleRecord(ctx, session, id); err == nil {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrAlreadyExists, "role %q already exists", id)
+	}
+
+	if err := runWrite(ctx, session,
+		"CREATE (r:Role {id: $id, display_name: $displayName})",
+		map[string]interface{}{"id": id, "displayName": displayName}); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	return &Role{ID: id, DisplayName: displayName}, nil
+}
+
+func (r *RoleRepository) Get(ctx context.Context, id string) (*Role, error) {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	record, err := getRoleRecord(ctx, session, id)
+	if err != nil {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrNotFound, "role %q not found", id)
+	}
+	return scanRole(record), nil
+}
+
+func (r *RoleRepository) Update(ctx context.Context, id, displayName string) (*Role, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+	if err := runWrite(ctx, session,
+		"MATCH (r:Role {id: $id}) SET r.display_name = $displayName",
+		map[string]interface{}{"id": id, "displayName": displayName}); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	return &Role{ID: id, DisplayName: displayName}, nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+	if err := runWrite(ctx, session, "MATCH (r:Role {id: $id}) DETACH DELETE r", map[string]interface{}{"id": id}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	// DETACH DELETE just dropped every HAS_ROLE edge into this role, for
+	// every user holding it - invalidate broadly rather than track them down.
+	r.client.publishInvalidation("")
+	return nil
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]*Role, error) {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	records, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) ([]*neo4j.Record, error) {
+		result, err := tx.Run(ctx, "MATCH (r:Role) RETURN r.id as id, r.display_name as displayName ORDER BY r.id", nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.Collect(ctx)
+	})
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	roles := make([]*Role, len(records))
+	for i, record := range records {
+		roles[i] = scanRole(record)
+	}
+	return roles, nil
+}
+
+func getRoleRecord(ctx context.Context, session neo4j.SessionWithContext, id string) (*neo4j.Record, error) {
+	return neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) (*neo4j.Record, error) {
+		result, err := tx.Run(ctx, "MATCH (r:Role {id: $id}) RETURN r.id as id, r.display_name as displayName", map[string]interface{}{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		return result.Single(ctx)
+	})
+}
+
+func scanRole(record *neo4j.Record) *Role {
+	id, _ := record.Get("id")
+	displayName, _ := record.Get("displayName")
+	role := &Role{}
+	if v, ok := id.(string); ok {
+		role.ID = v
+	}
+	if v, ok := displayName.(string); ok {
+		role.DisplayName = v
+	}
+	return role
+}
@@ -0,0 +1,524 @@
+package neo4j
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Bit is one of the permission kinds getPermissionQuery answers, packed into
+// AccountAccess.Bits so an AccessToken can hold every account a user can
+// reach as one small struct per account instead of one row per
+// (account, permission) pair.
+type Bit uint8
+
+const (
+	// BitRead covers read and can_view_transactions, which share a formula.
+	BitRead Bit = 1 << iota
+	// BitWrite covers write and can_initiate_payment's unconditional access
+	// paths (direct ownership, role); a POA-backed grant is re-checked
+	// against AccountAccess.POALimit at lookup time since its payment_limit
+	// varies per request amount.
+	BitWrite
+	// BitDownloadStatement covers can_download_statement, which shares
+	// read's formula.
+	BitDownloadStatement
+	// BitAccess covers can_access, granted only by direct ownership or role.
+	BitAccess
+)
+
+// permissionBit maps a PermissionRequest.Permission name to the Bit
+// AccessToken.Check consults, folding the same aliases getPermissionQuery's
+// switch does ("read"/"can_view_transactions", "write"/"can_initiate_payment").
+func permissionBit(permission string) (Bit, bool) {
+	switch permission {
+	case "read", "can_view_transactions":
+		return BitRead, true
+	case "write", "can_initiate_payment":
+		return BitWrite, true
+	case "can_download_statement":
+		return BitDownloadStatement, true
+	case "can_access":
+		return BitAccess, true
+	default:
+		return 0, false
+	}
+}
+
+// AccountAccess is one account's resolved permission state within an
+// AccessToken: Bits reflects what's true right now (as of the token's
+// resolve time), POALimit/ExpiresAt carry the data a raw bitmap can't, since
+// write access through a POA depends on the request's amount and every
+// POA/accountant-access edge is time-bound.
+type AccountAccess struct {
+	Bits Bit
+	// POALimit is the payment_limit off the user's HAS_POA edge to this
+	// account, if BitWrite came from that edge and no unconditional
+	// (ownership/role) edge also grants it; nil means either no POA
+	// (BitWrite came from ownership/role instead, or isn't set), an uncapped
+	// POA, or a POA whose cap is moot because ownership/role grants write
+	// unconditionally too.
+	POALimit *float64
+	// unconditionalWrite is true once an ownership/role edge (as opposed to
+	// a POA) has contributed BitWrite, so a POA edge merged before or after
+	// it never gets to stamp its cap onto an account that doesn't actually
+	// need it.
+	unconditionalWrite bool
+	// StartsAt is the latest starts_at among the POA/accountant-access edges
+	// that contributed to Bits; zero means every contributing edge is
+	// unconditional (direct ownership, role) or already open. Check treats
+	// the account as not yet trustworthy before this point, the mirror
+	// image of ExpiresAt at the other end of the window.
+	StartsAt time.Time
+	// ExpiresAt is the soonest expiry among the POA/accountant-access edges
+	// that contributed to Bits; zero means every contributing edge is
+	// unconditional (direct ownership, role) and never expires on its own.
+	ExpiresAt time.Time
+}
+
+// AccessToken is the per-user snapshot Client.getAccessToken resolves once
+// and then answers CheckPermission calls from in memory: every account the
+// user can reach, and the permissions they hold on each.
+type AccessToken struct {
+	UserID    string
+	CheckedAt string // the resolve query's bookmark, reused as PermissionResponse.CheckedAt
+	accounts  map[string]*AccountAccess
+}
+
+// Check answers whether permission holds on accountID as of now, using
+// amount only for BitWrite's POA payment_limit comparison. fresh is false
+// when now falls outside the account's nearest POA/accountant-access
+// window - either past its expiry or before its start - telling the caller
+// to fall back to Neo4j (and re-resolve) rather than trust a bit that may
+// not (yet, or still) hold.
+func (t *AccessToken) Check(accountID, permission string, amount *float64, now time.Time) (has bool, fresh bool) {
+	bit, ok := permissionBit(permission)
+	if !ok {
+		return false, false
+	}
+
+	access, ok := t.accounts[accountID]
+	if !ok {
+		// The resolve query is exhaustive over every account this user can
+		// reach, so an account missing from it means "no access" - and that
+		// fact doesn't expire on its own.
+		return false, true
+	}
+
+	if !access.ExpiresAt.IsZero() && !now.Before(access.ExpiresAt) {
+		return false, false
+	}
+	if !access.StartsAt.IsZero() && now.Before(access.StartsAt) {
+		return false, false
+	}
+
+	has = access.Bits&bit != 0
+	if has && bit == BitWrite && access.POALimit != nil && amount != nil && *amount > *access.POALimit {
+		has = false
+	}
+	return has, true
+}
+
+// defaultTokenTTL bounds how long an AccessToken is trusted without a
+// write-driven invalidation, the same safety net session-level caches lean
+// on against a missed invalidation.
+const defaultTokenTTL = 5 * time.Minute
+
+// defaultTokenCacheCapacity caps how many users' AccessTokens are held at
+// once; each is small (one struct per reachable account), so this favors a
+// high hit rate for the active-user population a deployment actually sees.
+const defaultTokenCacheCapacity = 10000
+
+type tokenEntry struct {
+	userID    string
+	token     *AccessToken
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// tokenCache is an LRU of AccessTokens keyed by user ID, TTL'd the same way
+// internal/cache.Cache TTLs PermissionResponses, plus an explicit
+// Invalidate/InvalidateAll pair the write paths below call through
+// Client.publishInvalidation instead of waiting out the TTL.
+type tokenCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	entries  map[string]*tokenEntry
+	order    *list.List // front = most recently used
+}
+
+func newTokenCache(capacity int, ttl time.Duration) *tokenCache {
+	return &tokenCache{
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[string]*tokenEntry),
+		order:    list.New(),
+	}
+}
+
+func (c *tokenCache) Get(userID string) (*AccessToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		c.removeLocked(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e.elem)
+	return e.token, true
+}
+
+func (c *tokenCache) Set(userID string, token *AccessToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[userID]; ok {
+		e.token = token
+		e.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(e.elem)
+		return
+	}
+
+	e := &tokenEntry{userID: userID, token: token, expiresAt: time.Now().Add(c.ttl)}
+	e.elem = c.order.PushFront(e)
+	c.entries[userID] = e
+
+	if c.capacity > 0 && len(c.entries) > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.removeLocked(oldest.Value.(*tokenEntry))
+		}
+	}
+}
+
+func (c *tokenCache) Invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[userID]; ok {
+		c.removeLocked(e)
+	}
+}
+
+func (c *tokenCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*tokenEntry)
+	c.order.Init()
+}
+
+func (c *tokenCache) removeLocked(e *tokenEntry) {
+	delete(c.entries, e.userID)
+	c.order.Remove(e.elem)
+}
+
+// publishInvalidation drops userID's cached AccessToken, or every cached
+// token when userID is "" - the broadcast form a repository write uses when
+// it can't cheaply name every affected user (e.g. a role's org membership
+// changing, which can flip access for everyone holding that role).
+func (c *Client) publishInvalidation(userID string) {
+	if c.tokens == nil {
+		return
+	}
+	if userID == "" {
+		c.tokens.InvalidateAll()
+		return
+	}
+	c.tokens.Invalidate(userID)
+}
+
+// InvalidateUser drops userID's cached AccessToken, forcing the next
+// CheckPermission for that user to re-resolve from Neo4j. Exposed so
+// callers outside the neo4j package (e.g. an admin RPC that edits the graph
+// directly) can invalidate without waiting out the TTL.
+func (c *Client) InvalidateUser(userID string) {
+	c.publishInvalidation(userID)
+}
+
+// getAccessToken returns userID's cached AccessToken, resolving a fresh one
+// on a cache miss (or a disabled cache, when tokens is nil).
+func (c *Client) getAccessToken(ctx context.Context, userID string) (*AccessToken, error) {
+	if c.tokens == nil {
+		return c.resolveAccessToken(ctx, userID)
+	}
+	if token, ok := c.tokens.Get(userID); ok {
+		return token, nil
+	}
+	token, err := c.resolveAccessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	c.tokens.Set(userID, token)
+	return token, nil
+}
+
+// roleAccessibleAccountsSubquery is roleAccessSubquery's token-resolution
+// twin: instead of testing one bound account, it walks the same three
+// HAS_ROLE/ALLOW_INHERIT/ALLOW_NO_INHERIT paths outward from user to collect
+// every account a role grants reach to. Same nested-CALL shape as
+// roleAccessSubquery and for the same reason: collect() has to sit in the
+// outer CALL's RETURN so a user with no role-granted accounts still yields
+// one row with roleAccountIds = [], instead of dropping the single
+// per-user row resolveAccessToken's final RETURN depends on.
+func (c *Client) roleAccessibleAccountsSubquery() string {
+	return fmt.Sprintf(`
+		CALL {
+			WITH user
+			CALL {
+				WITH user
+				MATCH (user)-[:HAS_ROLE]->(:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account:Account)
+				RETURN account
+				UNION
+				WITH user
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:CHILD_OF*0..%d]->(:Dept)-[:ALLOW_INHERIT]->(:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account:Account)
+				RETURN account
+				UNION
+				WITH user
+				MATCH (user)-[:BELONG_TO]->(:Dept)-[:ALLOW_NO_INHERIT]->(:Role)-[:MEMBER_OF]->(:Org)-[:OWNS]->(account:Account)
+				RETURN account
+			}
+			RETURN collect(DISTINCT account.id) as roleAccountIds
+		}
+	`, c.maxDeptDepth)
+}
+
+// resolveAccessToken queries every account userID can reach - through
+// direct ownership, a POA, an accountant-access grant, or a role (including
+// inherited department roles) - and folds each into one AccountAccess per
+// account. This is the "full resolve" CheckPermission's bitmap fast path
+// amortizes across many subsequent calls for the same user.
+func (c *Client) resolveAccessToken(ctx context.Context, userID string) (*AccessToken, error) {
+	session := c.session(ctx, nil)
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (user:User {id: $userId})
+		OPTIONAL MATCH (user)-[:OWNS]->(owned:Account)
+		WITH user, collect(DISTINCT owned.id) as ownedIds
+		OPTIONAL MATCH (user)-[poa:HAS_POA]->(poaAccount:Account)
+		WITH user, ownedIds, collect({accountId: poaAccount.id, startsAt: poa.starts_at, expiresAt: poa.expires_at, paymentLimit: poa.payment_limit}) as poaGrants
+		OPTIONAL MATCH (user)-[acc:HAS_ACCOUNTANT_ACCESS]->(accAccount:Account)
+		WITH user, ownedIds, poaGrants, collect({accountId: accAccount.id, startsAt: acc.starts_at, expiresAt: acc.expires_at}) as accountantGrants
+		` + c.roleAccessibleAccountsSubquery() + `
+		WITH ownedIds, poaGrants, accountantGrants, roleAccountIds
+		RETURN ownedIds, poaGrants, accountantGrants, roleAccountIds
+	`
+
+	records, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) ([]*neo4j.Record, error) {
+		result, err := tx.Run(ctx, query, map[string]interface{}{"userId": userID})
+		if err != nil {
+			return nil, err
+		}
+		return result.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		// Now that roleAccessibleAccountsSubquery's aggregation always
+		// yields a row, the only way this MATCH (user:User {id: $userId})
+		// drops its row is when no such User node exists - resolve that to
+		// an empty token rather than an error, the same "no rows, no
+		// permission" stance getPermissionQuery's OPTIONAL MATCH takes.
+		return &AccessToken{UserID: userID, CheckedAt: bookmarksToken(session.LastBookmarks()), accounts: map[string]*AccountAccess{}}, nil
+	}
+	record := records[0]
+
+	accounts := make(map[string]*AccountAccess)
+
+	if ownedIds, ok := record.Get("ownedIds"); ok {
+		for _, id := range asStringSlice(ownedIds) {
+			accounts[id] = mergeAccess(accounts[id], BitRead|BitWrite|BitDownloadStatement|BitAccess, nil, time.Time{}, time.Time{})
+		}
+	}
+
+	if roleAccountIds, ok := record.Get("roleAccountIds"); ok {
+		for _, id := range asStringSlice(roleAccountIds) {
+			accounts[id] = mergeAccess(accounts[id], BitRead|BitWrite|BitDownloadStatement|BitAccess, nil, time.Time{}, time.Time{})
+		}
+	}
+
+	if poaGrants, ok := record.Get("poaGrants"); ok {
+		for _, raw := range asSliceOfMaps(poaGrants) {
+			accountID, _ := raw["accountId"].(string)
+			if accountID == "" {
+				continue
+			}
+			startsAt := parseGrantTime(raw["startsAt"])
+			expiresAt := parseGrantTime(raw["expiresAt"])
+			var limit *float64
+			switch v := raw["paymentLimit"].(type) {
+			case float64:
+				limit = &v
+			case int64:
+				f := float64(v)
+				limit = &f
+			}
+			accounts[accountID] = mergeAccess(accounts[accountID], BitRead|BitWrite|BitDownloadStatement, limit, startsAt, expiresAt)
+		}
+	}
+
+	if accountantGrants, ok := record.Get("accountantGrants"); ok {
+		for _, raw := range asSliceOfMaps(accountantGrants) {
+			accountID, _ := raw["accountId"].(string)
+			if accountID == "" {
+				continue
+			}
+			startsAt := parseGrantTime(raw["startsAt"])
+			expiresAt := parseGrantTime(raw["expiresAt"])
+			accounts[accountID] = mergeAccess(accounts[accountID], BitRead|BitDownloadStatement, nil, startsAt, expiresAt)
+		}
+	}
+
+	return &AccessToken{
+		UserID:    userID,
+		CheckedAt: bookmarksToken(session.LastBookmarks()),
+		accounts:  accounts,
+	}, nil
+}
+
+// mergeAccess folds one more contributing edge into account's accumulated
+// AccountAccess: bits OR together, StartsAt keeps the latest non-zero start
+// seen so far and ExpiresAt keeps the soonest non-zero expiry seen so far (a
+// zero bound, meaning "unconditional", never overrides an edge that does
+// have one, since the account can still fall back to that unconditional
+// path once the time-bound one lapses - but Check conservatively treats the
+// whole account as not (yet, or still) trustworthy outside the nearest
+// bound, deferring to Neo4j to sort out which path still holds).
+//
+// poaLimit is only stamped onto POALimit when this edge is the one granting
+// BitWrite AND no ownership/role edge has contributed it unconditionally -
+// an uncapped write path makes a POA's cap moot, whichever order the edges
+// are merged in.
+func mergeAccess(existing *AccountAccess, bits Bit, poaLimit *float64, startsAt, expiresAt time.Time) *AccountAccess {
+	if existing == nil {
+		existing = &AccountAccess{}
+	}
+	if bits&BitWrite != 0 && poaLimit == nil {
+		existing.unconditionalWrite = true
+		existing.POALimit = nil
+	}
+	existing.Bits |= bits
+	if bits&BitWrite != 0 && poaLimit != nil && !existing.unconditionalWrite {
+		existing.POALimit = poaLimit
+	}
+	if !startsAt.IsZero() {
+		if existing.StartsAt.IsZero() || startsAt.After(existing.StartsAt) {
+			existing.StartsAt = startsAt
+		}
+	}
+	if !expiresAt.IsZero() {
+		if existing.ExpiresAt.IsZero() || expiresAt.Before(existing.ExpiresAt) {
+			existing.ExpiresAt = expiresAt
+		}
+	}
+	return existing
+}
+
+func parseGrantTime(v interface{}) time.Time {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(grantTimeLayout, s)
+	if err != nil {
+		// Fixture-loaded edges carry RFC3339 timestamps (with a zone)
+		// instead of grantTimeLayout.
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return time.Time{}
+		}
+	}
+	return t
+}
+
+// checkPermissionFromToken is CheckPermission's bitmap fast path: it skips
+// straight past a Cypher round-trip when userID's AccessToken can answer
+// req on its own. handled is false - meaning the caller should fall back to
+// the normal per-call query - whenever the bitmap can't be trusted for this
+// request: explain mode (the bitmap has no grant-edge detail to explain),
+// a non-default consistency requirement (the bitmap is inherently
+// eventually consistent), an unrecognized permission, or a resolved token
+// whose nearest POA/accountant-access expiry has already passed.
+func (c *Client) checkPermissionFromToken(ctx context.Context, req *pb.PermissionRequest, startTime time.Time) (resp *pb.PermissionResponse, handled bool, err error) {
+	if c.tokens == nil || req.Context["explain"] == "true" || !isDefaultConsistency(req.Consistency) {
+		return nil, false, nil
+	}
+	if _, ok := permissionBit(req.Permission); !ok {
+		return nil, false, nil
+	}
+
+	token, tokenErr := c.getAccessToken(ctx, req.Actor)
+	if tokenErr != nil {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	if dateStr, ok := req.Context["test_date"]; ok {
+		if parsed := parseGrantTime(dateStr); !parsed.IsZero() {
+			now = parsed
+		}
+	}
+
+	var amount *float64
+	if req.Permission == "can_initiate_payment" || req.Permission == "write" {
+		a := 1000.0
+		if amtStr, ok := req.Context["amount"]; ok {
+			if parsed, parseErr := strconv.ParseFloat(amtStr, 64); parseErr == nil {
+				a = parsed
+			}
+		}
+		amount = &a
+	}
+
+	has, fresh := token.Check(req.Resource, req.Permission, amount, now)
+	if !fresh {
+		return nil, false, nil
+	}
+
+	permissionship := pb.Permissionship_PERMISSIONSHIP_NO_PERMISSION
+	if has {
+		permissionship = pb.Permissionship_PERMISSIONSHIP_HAS_PERMISSION
+	}
+	return &pb.PermissionResponse{
+		HasPermission:  has,
+		Permissionship: int32(permissionship),
+		Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
+		ResponseTimeMs: float64(time.Since(startTime).Milliseconds()),
+		CheckedAt:      token.CheckedAt,
+	}, true, nil
+}
+
+// isDefaultConsistency is true for a nil Consistency or one with no
+// requirement set - the only cases the bitmap fast path trusts, since
+// at_least_as_fresh/at_exact_snapshot/fully_consistent all ask for a
+// guarantee an in-process cache can't make.
+func isDefaultConsistency(c *pb.Consistency) bool {
+	if c == nil {
+		return true
+	}
+	return c.GetRequirement() == nil
+}
+
+func asStringSlice(value interface{}) []string {
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package neo4j
+
+import (
+	"context"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// UserRepository manages User nodes directly, so admin tooling and seeding
+// don't have to shell out to cypher-shell to create the actors CheckPermission
+// reasons about.
+type UserRepository struct {
+	client *Client
+}
+
+func NewUserRepository(client *Client) *UserRepository {
+	return &UserRepository{client: client}
+}
+
+func (r *UserRepository) Create(ctx context.Context, id, displayName string) (*User, error) {
+	if id == "" {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "user id is required")
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	if _, err := getUserRecord(ctx, session, id); err == nil {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrAlreadyExists, "user %q already exists", id)
+	}
+
+	if err := runWrite(ctx, session,
+		"CREATE (u:User {id: $id, display_name: $displayName})",
+		map[string]interface{}{"id": id, "displayName": displayName}); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	return &User{ID: id, DisplayName: displayName}, nil
+}
+
+func (r *UserRepository) Get(ctx context.Context, id string) (*User, error) {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	record, err := getUserRecord(ctx, session, id)
+	if err != nil {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrNotFound, "user %q not found", id)
+	}
+	return scanUser(record), nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, id, displayName string) (*User, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+	if err := runWrite(ctx, session,
+		"MATCH (u:User {id: $id}) SET u.display_name = $displayName",
+		map[string]interface{}{"id": id, "displayName": displayName}); err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	return &User{ID: id, DisplayName: displayName}, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+	if err := runWrite(ctx, session, "MATCH (u:User {id: $id}) DETACH DELETE u", map[string]interface{}{"id": id}); err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+	r.client.publishInvalidation(id)
+	return nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]*User, error) {
+	session := r.client.session(ctx, nil)
+	defer session.Close(ctx)
+
+	records, err := neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) ([]*neo4j.Record, error) {
+		result, err := tx.Run(ctx, "MATCH (u:User) RETURN u.id as id, u.display_name as displayName ORDER BY u.id", nil)
+		if err != nil {
+			return nil, err
+		}
+		return result.Collect(ctx)
+	})
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	users := make([]*User, len(records))
+	for i, record := range records {
+		users[i] = scanUser(record)
+	}
+	return users, nil
+}
+
+func getUserRecord(ctx context.Context, session neo4j.SessionWithContext, id string) (*neo4j.Record, error) {
+	return neo4j.ExecuteRead(ctx, session, func(tx neo4j.ManagedTransaction) (*neo4j.Record, error) {
+		result, err := tx.Run(ctx, "MATCH (u:User {id: $id}) RETURN u.id as id, u.display_name as displayName", map[string]interface{}{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		return result.Single(ctx)
+	})
+}
+
+func scanUser(record *neo4j.Record) *User {
+	id, _ := record.Get("id")
+	displayName, _ := record.Get("displayName")
+	user := &User{}
+	if v, ok := id.(string); ok {
+		user.ID = v
+	}
+	if v, ok := displayName.(string); ok {
+		user.DisplayName = v
+	}
+	return user
+}
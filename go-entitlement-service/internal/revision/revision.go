@@ -0,0 +1,37 @@
+// Package revision defines a backend-agnostic "how fresh must this read be"
+// abstraction so SpiceDB's ZedToken, Neo4j's bookmarks, and a GraphQL
+// service's own versioning scheme can all satisfy the same Consistency
+// contract from the proto.
+package revision
+
+import "context"
+
+// Token is an opaque, backend-specific revision marker (a SpiceDB ZedToken
+// string, a Neo4j bookmark, a GraphQL transaction id, ...). Tokens are only
+// meaningful to the backend that issued them; callers should treat them as
+// opaque strings to store and pass back, not to parse.
+type Token string
+
+// Requirement mirrors the proto Consistency oneof so backend code doesn't
+// need to import the pb package just to branch on consistency mode.
+type Requirement struct {
+	MinimizeLatency bool
+	AtLeastAsFresh  Token
+	AtExactSnapshot Token
+	FullyConsistent bool
+}
+
+// IsZero reports whether no explicit requirement was set, in which case a
+// backend should fall back to its own default (the SpiceDB client sends
+// fully_consistent, not SpiceDB's own minimize_latency default).
+func (r Requirement) IsZero() bool {
+	return !r.MinimizeLatency && r.AtLeastAsFresh == "" && r.AtExactSnapshot == "" && !r.FullyConsistent
+}
+
+// Waiter is implemented by backends whose consistency model requires
+// blocking until a given token's writes are visible (e.g. Neo4j waiting for
+// a bookmark) rather than passing the token straight through to the query
+// engine as SpiceDB does.
+type Waiter interface {
+	WaitForRevision(ctx context.Context, token Token) error
+}
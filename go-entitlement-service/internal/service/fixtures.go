@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+)
+
+// LoadFixtures wipes and reseeds every backend named in implementations (or,
+// if empty, every registered backend) with schema and rels, skipping any
+// backend that doesn't implement backend.FixtureBackend (GraphQL, which
+// shares Neo4j's underlying store) rather than erroring. It returns the
+// names of the backends actually reseeded.
+func (s *Service) LoadFixtures(ctx context.Context, schema string, rels []backend.Relationship, implementations []string) ([]string, error) {
+	if len(implementations) == 0 {
+		implementations = s.registry.Names()
+	}
+
+	loaded := make([]string, 0, len(implementations))
+	for _, name := range implementations {
+		b, err := s.registry.Get(name)
+		if err != nil {
+			return loaded, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+		}
+
+		fixtureBackend, ok := b.(backend.FixtureBackend)
+		if !ok {
+			continue
+		}
+
+		if err := fixtureBackend.ClearAll(ctx); err != nil {
+			return loaded, err
+		}
+		if err := fixtureBackend.LoadSchema(ctx, schema); err != nil {
+			return loaded, err
+		}
+		if err := fixtureBackend.WriteRelationships(ctx, rels); err != nil {
+			return loaded, err
+		}
+		loaded = append(loaded, name)
+	}
+
+	return loaded, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// LookupResources answers "which resourceType objects can actor do
+// permission on", aggregating the backend's streaming lookup into a single
+// slice. Only backends implementing backend.ResourceLookup support this;
+// GraphQL currently doesn't.
+func (s *Service) LookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency, implementation string) ([]*pb.LookupResourcesItem, error) {
+	if implementation == "" {
+		implementation = BackendSpiceDB
+	}
+
+	lookup, err := s.resourceLookupBackend(implementation)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup.LookupResources(ctx, actor, resourceType, permission, caveatContext, consistency)
+}
+
+// StreamLookupResources is LookupResources without the aggregation, calling
+// onItem once per resource as the backend's stream yields it.
+func (s *Service) StreamLookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency, implementation string, onItem func(*pb.LookupResourcesItem) error) error {
+	if implementation == "" {
+		implementation = BackendSpiceDB
+	}
+
+	lookup, err := s.resourceLookupBackend(implementation)
+	if err != nil {
+		return err
+	}
+
+	return lookup.StreamLookupResources(ctx, actor, resourceType, permission, caveatContext, consistency, onItem)
+}
+
+// LookupSubjects answers "who can do permission on resourceType:resourceID",
+// the inverse of LookupResources.
+func (s *Service) LookupSubjects(ctx context.Context, resourceType, resourceID, permission, subjectType string, caveatContext map[string]string, consistency *pb.Consistency, implementation string) ([]string, error) {
+	if implementation == "" {
+		implementation = BackendSpiceDB
+	}
+
+	lookup, err := s.resourceLookupBackend(implementation)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup.LookupSubjects(ctx, resourceType, resourceID, permission, subjectType, caveatContext, consistency)
+}
+
+func (s *Service) resourceLookupBackend(implementation string) (backend.ResourceLookup, error) {
+	b, err := s.registry.Get(implementation)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	lookup, ok := b.(backend.ResourceLookup)
+	if !ok {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "backend %q does not support resource lookup", implementation)
+	}
+	return lookup, nil
+}
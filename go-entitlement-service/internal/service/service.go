@@ -3,161 +3,376 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	"github.com/adityakumar/labs/go-entitlement-service/internal/cache"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	"github.com/adityakumar/labs/go-entitlement-service/internal/metrics"
 	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
 )
 
-type SpiceDBClient interface {
-	CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error)
+// Default size/freshness for the single-check cache. Entries are small
+// (one PermissionResponse) and authorization traffic is read-heavy and
+// bursty, so a generous capacity with a short TTL favors hit rate while
+// keeping staleness bounded.
+const (
+	defaultCacheCapacity = 10000
+	defaultCacheTTL      = 2 * time.Second
+)
+
+// Names the three backends are registered under. Routing directives (see
+// internal/backend/routing.go) reference backends by these names.
+const (
+	BackendSpiceDB = "spicedb"
+	BackendNeo4j   = "neo4j"
+	BackendGraphQL = "graphql"
+)
+
+type Service struct {
+	registry   *backend.Registry
+	divergence metrics.DivergenceSink
+	cache      *cache.Cache
 }
 
-type Neo4jClient interface {
-	CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error)
+// NewService registers the three built-in backends under their conventional
+// names. A nil client is registered as unavailable rather than omitted, so a
+// routing directive that names it fails with a clear error instead of
+// "unknown backend".
+func NewService(spice, neo4j, gql backend.PermissionBackend) *Service {
+	registry := backend.NewRegistry()
+	registry.Register(BackendSpiceDB, spice)
+	registry.Register(BackendNeo4j, neo4j)
+	registry.Register(BackendGraphQL, gql)
+	return &Service{
+		registry:   registry,
+		divergence: metrics.NewPrometheusSink(),
+		cache:      cache.New(defaultCacheCapacity, defaultCacheTTL),
+	}
 }
 
-type GraphQLClient interface {
-	CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error)
+// NewServiceWithRegistry builds a Service around an arbitrary set of
+// registered backends, for callers that want to add backends beyond the
+// built-in three (e.g. in tests, or future backend additions).
+func NewServiceWithRegistry(registry *backend.Registry, divergence metrics.DivergenceSink) *Service {
+	if divergence == nil {
+		divergence = metrics.NewPrometheusSink()
+	}
+	return &Service{
+		registry:   registry,
+		divergence: divergence,
+		cache:      cache.New(defaultCacheCapacity, defaultCacheTTL),
+	}
 }
 
-type Service struct {
-	Spice   SpiceDBClient
-	Neo4j   Neo4jClient
-	GraphQL GraphQLClient
+// CheckPermission parses the `implementation` request field as a routing
+// directive and dispatches accordingly. A bare name ("spicedb") or the
+// legacy "both" value behave exactly as before; "primary:x,shadow:y",
+// "quorum:x,y:N", and "race:x,y" select the newer routing modes.
+func (s *Service) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	raw := req.Context["implementation"]
+	if raw == "" {
+		raw = BackendSpiceDB
+	}
+
+	directive, err := backend.ParseDirective(raw)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrValidationFailed, err)
+	}
+
+	switch directive.Mode {
+	case backend.ModeShadow:
+		return s.checkShadow(ctx, req, directive)
+	case backend.ModeQuorum:
+		return s.checkQuorum(ctx, req, directive)
+	case backend.ModeRace:
+		return s.checkRace(ctx, req, directive)
+	default:
+		return s.checkSingle(ctx, req, directive.Primary)
+	}
 }
 
-func NewService(spice SpiceDBClient, neo4j Neo4jClient, gql GraphQLClient) *Service {
-	return &Service{Spice: spice, Neo4j: neo4j, GraphQL: gql}
+// checkSingle dispatches to exactly one named backend, serving from the
+// cache when a recent identical check is still fresh and coalescing
+// concurrent misses for the same tuple into one backend call.
+func (s *Service) checkSingle(ctx context.Context, req *pb.PermissionRequest, name string) (*pb.PermissionResponse, error) {
+	b, err := s.registry.Get(name)
+	if err != nil {
+		return backendUnavailableResponse(implementationOf(name), err.Error()), nil
+	}
+
+	key := cacheKey(name, req)
+	return s.cache.GetOrLoad(ctx, key, func(ctx context.Context) (*pb.PermissionResponse, error) {
+		return b.CheckPermission(ctx, req)
+	})
 }
 
-// CheckPermission routes the request to the appropriate backend based on implementation
-func (s *Service) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	// Default to SpiceDB if no specific implementation is requested
-	implementation := pb.Implementation_IMPLEMENTATION_SPICEDB
-
-	// Check if implementation is specified in context
-	if implStr, ok := req.Context["implementation"]; ok {
-		switch implStr {
-		case "spicedb":
-			implementation = pb.Implementation_IMPLEMENTATION_SPICEDB
-		case "neo4j":
-			implementation = pb.Implementation_IMPLEMENTATION_NEO4J
-		case "graphql":
-			implementation = pb.Implementation_IMPLEMENTATION_GRAPHQL
-		case "both":
-			implementation = pb.Implementation_IMPLEMENTATION_BOTH
-		}
+// cacheKey builds the cache.Key for a single-backend check against req.
+func cacheKey(backendName string, req *pb.PermissionRequest) cache.Key {
+	return cache.Key{
+		Backend:          backendName,
+		Actor:            req.Actor,
+		Resource:         req.Resource,
+		Permission:       req.Permission,
+		ConsistencyToken: consistencyToken(req.Consistency),
+		ContextHash:      cache.HashContext(req.Context),
 	}
+}
 
-	switch implementation {
-	case pb.Implementation_IMPLEMENTATION_NEO4J:
-		if s.Neo4j == nil {
-			return &pb.PermissionResponse{
-				HasPermission:  false,
-				Implementation: pb.Implementation_IMPLEMENTATION_NEO4J,
-				ErrorMessage:   "Neo4j backend not available - please ensure Neo4j is running on localhost:7687",
-			}, nil
-		}
-		return s.Neo4j.CheckPermission(ctx, req)
-	case pb.Implementation_IMPLEMENTATION_GRAPHQL:
-		if s.GraphQL == nil {
-			return &pb.PermissionResponse{
-				HasPermission:  false,
-				Implementation: pb.Implementation_IMPLEMENTATION_GRAPHQL,
-				ErrorMessage:   "GraphQL backend not available - please ensure GraphQL is running on localhost:4000",
-			}, nil
-		}
-		return s.GraphQL.CheckPermission(ctx, req)
-	case pb.Implementation_IMPLEMENTATION_BOTH:
-		return s.checkBothImplementations(ctx, req)
-	case pb.Implementation_IMPLEMENTATION_SPICEDB:
-		fallthrough
+// consistencyToken collapses a Consistency requirement into a string that's
+// part of the cache key, so e.g. an at_exact_snapshot read never serves a
+// response cached under a different snapshot (or under minimize_latency).
+func consistencyToken(c *pb.Consistency) string {
+	switch req := c.GetRequirement().(type) {
+	case *pb.Consistency_AtLeastAsFresh:
+		return "at_least_as_fresh:" + req.AtLeastAsFresh
+	case *pb.Consistency_AtExactSnapshot:
+		return "at_exact_snapshot:" + req.AtExactSnapshot
+	case *pb.Consistency_FullyConsistent:
+		return "fully_consistent"
 	default:
-		if s.Spice == nil {
-			return &pb.PermissionResponse{
-				HasPermission:  false,
-				Implementation: pb.Implementation_IMPLEMENTATION_SPICEDB,
-				ErrorMessage:   "SpiceDB backend not available - please ensure SpiceDB is running on localhost:50051",
-			}, nil
-		}
-		return s.Spice.CheckPermission(ctx, req)
+		return "minimize_latency"
+	}
+}
+
+// checkShadow checks Primary and returns its result. Each backend in
+// Shadows is fired in the background (not awaited by the caller); its
+// result is compared against Primary's and any divergence recorded via the
+// divergence sink. This subsumes the legacy "both" behavior, which is now
+// just primary:spicedb,shadow:neo4j.
+func (s *Service) checkShadow(ctx context.Context, req *pb.PermissionRequest, d backend.Directive) (*pb.PermissionResponse, error) {
+	primary, err := s.registry.Get(d.Primary)
+	if err != nil {
+		return backendUnavailableResponse(implementationOf(d.Primary), err.Error()), nil
+	}
+
+	primaryResp, primaryErr := primary.CheckPermission(ctx, req)
+	if primaryErr != nil {
+		return nil, primaryErr
+	}
+	if primaryResp != nil {
+		primaryResp.Implementation = pb.Implementation_IMPLEMENTATION_BOTH
+	}
+
+	// Shadow backends run detached from the request's context so a client
+	// cancelling the RPC doesn't cut the comparison short; they get their own
+	// background context instead.
+	for _, name := range d.Shadows {
+		name := name
+		go func() {
+			shadow, err := s.registry.Get(name)
+			if err != nil {
+				fmt.Printf("shadow backend %q unavailable: %v\n", name, err)
+				return
+			}
+			shadowResp, err := shadow.CheckPermission(context.Background(), req)
+			if err != nil {
+				fmt.Printf("shadow backend %q error: %v\n", name, err)
+				return
+			}
+			if primaryResp == nil || shadowResp == nil {
+				return
+			}
+			s.divergence.RecordDivergence(req.Actor, req.Resource, req.Permission, map[string]bool{
+				d.Primary: primaryResp.HasPermission,
+				name:      shadowResp.HasPermission,
+			})
+		}()
 	}
+
+	return primaryResp, nil
 }
 
-// checkBothImplementations runs the same request against both SpiceDB and Neo4j for comparison
-func (s *Service) checkBothImplementations(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	var spiceResult, neo4jResult *pb.PermissionResponse
-	var spiceErr, neo4jErr error
+// checkQuorum checks every backend in d.Backends concurrently and grants
+// only when at least d.Threshold of them grant; any other outcome is a deny.
+// Backends that error are excluded from the vote and from the verdicts
+// recorded in the divergence sink; if fewer than d.Threshold backends
+// answer, it returns ErrBackendUnavailable.
+func (s *Service) checkQuorum(ctx context.Context, req *pb.PermissionRequest, d backend.Directive) (*pb.PermissionResponse, error) {
+	type vote struct {
+		name   string
+		result bool
+		err    error
+	}
 
-	// Run both implementations concurrently
-	spiceChan := make(chan struct{})
-	neo4jChan := make(chan struct{})
+	votes := make(chan vote, len(d.Backends))
+	for _, name := range d.Backends {
+		name := name
+		go func() {
+			b, err := s.registry.Get(name)
+			if err != nil {
+				votes <- vote{name: name, err: err}
+				return
+			}
+			resp, err := b.CheckPermission(ctx, req)
+			if err != nil {
+				votes <- vote{name: name, err: err}
+				return
+			}
+			votes <- vote{name: name, result: resp.HasPermission}
+		}()
+	}
 
-	go func() {
-		defer close(spiceChan)
-		if s.Spice != nil {
-			spiceResult, spiceErr = s.Spice.CheckPermission(ctx, req)
+	results := make(map[string]bool, len(d.Backends))
+	grantCount, denyCount := 0, 0
+	for i := 0; i < len(d.Backends); i++ {
+		v := <-votes
+		if v.err != nil {
+			fmt.Printf("quorum backend %q error: %v\n", v.name, v.err)
+			continue
+		}
+		results[v.name] = v.result
+		if v.result {
+			grantCount++
 		} else {
-			spiceErr = fmt.Errorf("SpiceDB backend not available")
+			denyCount++
 		}
-	}()
+	}
 
-	go func() {
-		defer close(neo4jChan)
-		if s.Neo4j != nil {
-			neo4jResult, neo4jErr = s.Neo4j.CheckPermission(ctx, req)
-		} else {
-			neo4jErr = fmt.Errorf("Neo4j backend not available")
+	s.divergence.RecordDivergence(req.Actor, req.Resource, req.Permission, results)
+
+	if grantCount+denyCount < d.Threshold {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable,
+			"quorum requires %d responses, only %d backends answered", d.Threshold, grantCount+denyCount)
+	}
+
+	return &pb.PermissionResponse{
+		HasPermission:  grantCount >= d.Threshold,
+		Implementation: pb.Implementation_IMPLEMENTATION_BOTH,
+	}, nil
+}
+
+// checkRace checks every backend in d.Backends concurrently and returns the
+// first successful response; the rest are cancelled via the shared context.
+// It returns an error (the last one seen) only when every backend fails.
+func (s *Service) checkRace(ctx context.Context, req *pb.PermissionRequest, d backend.Directive) (*pb.PermissionResponse, error) {
+	type result struct {
+		resp *pb.PermissionResponse
+		err  error
+	}
+
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, len(d.Backends))
+	for _, name := range d.Backends {
+		name := name
+		go func() {
+			b, err := s.registry.Get(name)
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			resp, err := b.CheckPermission(raceCtx, req)
+			results <- result{resp: resp, err: err}
+		}()
+	}
+
+	// Wait for the first success, not just the first response - a fast-
+	// failing backend shouldn't sink the race for a slower one that would
+	// have succeeded. Only give up once every backend has reported in.
+	var lastErr error
+	for i := 0; i < len(d.Backends); i++ {
+		r := <-results
+		if r.err != nil {
+			lastErr = r.err
+			continue
 		}
-	}()
-
-	// Wait for both to complete
-	<-spiceChan
-	<-neo4jChan
-
-	// If both have errors, return comparison error
-	if spiceErr != nil && neo4jErr != nil {
-		return &pb.PermissionResponse{
-			HasPermission:  false,
-			Implementation: pb.Implementation_IMPLEMENTATION_BOTH,
-			ErrorMessage:   fmt.Sprintf("Both backends unavailable - SpiceDB: %v, Neo4j: %v", spiceErr, neo4jErr),
-		}, nil
-	}
-
-	// If one has an error, log it but continue with the working one
-	if spiceErr != nil {
-		fmt.Printf("SpiceDB error (using Neo4j only): %v\n", spiceErr)
-		if neo4jResult != nil {
-			neo4jResult.Implementation = pb.Implementation_IMPLEMENTATION_BOTH
-			neo4jResult.ErrorMessage = fmt.Sprintf("SpiceDB unavailable, using Neo4j only: %s", neo4jResult.ErrorMessage)
-			return neo4jResult, nil
+		cancel() // we have our winner; signal the rest to abort
+		if r.resp != nil {
+			r.resp.Implementation = pb.Implementation_IMPLEMENTATION_BOTH
 		}
+		return r.resp, nil
 	}
+	return nil, lastErr
+}
+
+// BulkCheckPermission answers many requests with as few backend round-trips
+// as possible: requests that route to a single named backend (the common
+// case) are grouped by that backend and, if it implements
+// backend.BulkBackend, answered in one call; everything else (shadow/quorum/
+// race directives, or a backend without a bulk API) falls back to the usual
+// cached/coalesced CheckPermission path. Results are returned in request
+// order.
+func (s *Service) BulkCheckPermission(ctx context.Context, requests []*pb.PermissionRequest) ([]*pb.PermissionResponse, error) {
+	results := make([]*pb.PermissionResponse, len(requests))
+	groups := make(map[string][]int)
 
-	if neo4jErr != nil {
-		fmt.Printf("Neo4j error (using SpiceDB only): %v\n", neo4jErr)
-		if spiceResult != nil {
-			spiceResult.Implementation = pb.Implementation_IMPLEMENTATION_BOTH
-			spiceResult.ErrorMessage = fmt.Sprintf("Neo4j unavailable, using SpiceDB only: %s", spiceResult.ErrorMessage)
-			return spiceResult, nil
+	for i, req := range requests {
+		raw := req.Context["implementation"]
+		if raw == "" {
+			raw = BackendSpiceDB
 		}
+		directive, err := backend.ParseDirective(raw)
+		if err != nil || directive.Mode != backend.ModeSingle {
+			resp, err := s.CheckPermission(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = resp
+			continue
+		}
+		groups[directive.Primary] = append(groups[directive.Primary], i)
 	}
 
-	// Both succeeded - compare results and return SpiceDB result with comparison info
-	if spiceResult != nil && neo4jResult != nil {
-		// Log comparison for debugging
-		if spiceResult.HasPermission != neo4jResult.HasPermission {
-			fmt.Printf("MISMATCH: SpiceDB=%t, Neo4j=%t for actor=%s, resource=%s, permission=%s\n",
-				spiceResult.HasPermission, neo4jResult.HasPermission,
-				req.Actor, req.Resource, req.Permission)
-		} else {
-			fmt.Printf("MATCH: Both backends returned %t for actor=%s, resource=%s, permission=%s\n",
-				spiceResult.HasPermission, req.Actor, req.Resource, req.Permission)
+	for name, indices := range groups {
+		b, err := s.registry.Get(name)
+		if err != nil {
+			resp := backendUnavailableResponse(implementationOf(name), err.Error())
+			for _, idx := range indices {
+				results[idx] = resp
+			}
+			continue
+		}
+
+		bulkBackend, ok := b.(backend.BulkBackend)
+		if !ok {
+			for _, idx := range indices {
+				resp, err := s.checkSingle(ctx, requests[idx], name)
+				if err != nil {
+					return nil, err
+				}
+				results[idx] = resp
+			}
+			continue
+		}
+
+		group := make([]*pb.PermissionRequest, len(indices))
+		for j, idx := range indices {
+			group[j] = requests[idx]
 		}
+		groupResults, err := bulkBackend.CheckBulkPermissions(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		for j, idx := range indices {
+			results[idx] = groupResults[j]
+		}
+	}
+
+	return results, nil
+}
 
-		// Return SpiceDB result but mark as BOTH implementation
-		spiceResult.Implementation = pb.Implementation_IMPLEMENTATION_BOTH
-		return spiceResult, nil
+func implementationOf(name string) pb.Implementation {
+	switch name {
+	case BackendNeo4j:
+		return pb.Implementation_IMPLEMENTATION_NEO4J
+	case BackendGraphQL:
+		return pb.Implementation_IMPLEMENTATION_GRAPHQL
+	default:
+		return pb.Implementation_IMPLEMENTATION_SPICEDB
 	}
+}
 
-	return nil, fmt.Errorf("unexpected state in both implementations check")
+// backendUnavailableResponse builds a PermissionResponse for a backend that
+// was never wired up (e.g. Neo4j failed to connect at startup), tagged with
+// ErrBackendUnavailable so callers can distinguish this from a legitimate
+// permission denial without parsing ErrorMessage.
+func backendUnavailableResponse(impl pb.Implementation, message string) *pb.PermissionResponse {
+	return &pb.PermissionResponse{
+		HasPermission:  false,
+		Implementation: impl,
+		ErrorMessage:   message,
+		ErrorCode:      pb.ErrorCode_ERROR_CODE_BACKEND_UNAVAILABLE,
+	}
 }
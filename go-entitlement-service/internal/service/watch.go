@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// WatchPermissions subscribes to PermissionEvents for targets on the named
+// backend, so a caller can react to permission-relevant changes instead of
+// polling CheckPermission. Only backends implementing backend.Watcher
+// support this; GraphQL currently doesn't.
+func (s *Service) WatchPermissions(ctx context.Context, targets []*pb.WatchTarget, implementation string) (<-chan *pb.PermissionEvent, error) {
+	if len(targets) == 0 {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "at least one watch target is required")
+	}
+	if implementation == "" {
+		implementation = BackendSpiceDB
+	}
+
+	b, err := s.registry.Get(implementation)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrBackendUnavailable, err)
+	}
+
+	watcher, ok := b.(backend.Watcher)
+	if !ok {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrValidationFailed, "backend %q does not support watching", implementation)
+	}
+
+	return watcher.Watch(ctx, targets)
+}
+
+// BackendNameOf maps an Implementation enum value to the registry name it
+// was registered under, for callers (like the gRPC handler) that only have
+// the proto enum available.
+func BackendNameOf(impl pb.Implementation) string {
+	switch impl {
+	case pb.Implementation_IMPLEMENTATION_NEO4J:
+		return BackendNeo4j
+	case pb.Implementation_IMPLEMENTATION_GRAPHQL:
+		return BackendGraphQL
+	default:
+		return BackendSpiceDB
+	}
+}
@@ -0,0 +1,87 @@
+package spicedb
+
+import (
+	"context"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// CheckBulkPermissions answers every request in a single SpiceDB
+// CheckBulkPermissions call instead of one CheckPermission round-trip per
+// request. Results are returned in the same order as requests.
+func (c *Client) CheckBulkPermissions(ctx context.Context, requests []*pb.PermissionRequest) ([]*pb.PermissionResponse, error) {
+	items := make([]*v1.CheckBulkPermissionsRequestItem, len(requests))
+	for i, req := range requests {
+		item := &v1.CheckBulkPermissionsRequestItem{
+			Resource: &v1.ObjectReference{
+				ObjectType: "account",
+				ObjectId:   req.GetResource(),
+			},
+			Permission: req.GetPermission(),
+			Subject: &v1.SubjectReference{
+				Object: &v1.ObjectReference{
+					ObjectType: "user",
+					ObjectId:   req.GetActor(),
+				},
+			},
+		}
+		if len(req.Context) > 0 {
+			contextMap := make(map[string]interface{}, len(req.Context))
+			for k, v := range req.Context {
+				contextMap[k] = v
+			}
+			contextStruct, err := structpb.NewStruct(contextMap)
+			if err != nil {
+				return nil, entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+			}
+			item.Context = contextStruct
+		}
+		items[i] = item
+	}
+
+	resp, err := c.client.CheckBulkPermissions(ctx, &v1.CheckBulkPermissionsRequest{
+		Items:       items,
+		Consistency: toSpiceDBConsistency(requests[0].GetConsistency()),
+	})
+	if err != nil {
+		typedErr := entitlementerrors.New(classifyGRPCError(err), err)
+		return nil, typedErr
+	}
+
+	if len(resp.Pairs) != len(requests) {
+		return nil, entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable,
+			"CheckBulkPermissions returned %d results for %d requests", len(resp.Pairs), len(requests))
+	}
+
+	results := make([]*pb.PermissionResponse, len(requests))
+	for i, pair := range resp.Pairs {
+		results[i] = c.bulkPairToResponse(requests[i].GetPermission(), pair)
+	}
+	return results, nil
+}
+
+func (c *Client) bulkPairToResponse(permission string, pair *v1.CheckBulkPermissionsPair) *pb.PermissionResponse {
+	if respErr := pair.GetError(); respErr != nil {
+		typedErr := entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "%s", respErr.GetMessage())
+		return errorResponse(typedErr)
+	}
+
+	item := pair.GetItem()
+	if item == nil {
+		return errorResponse(entitlementerrors.Newf(entitlementerrors.ErrBackendUnavailable, "missing bulk check result"))
+	}
+
+	hasPermission := item.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	missingContext, caveatName := c.missingContext(permission, item.PartialCaveatInfo)
+
+	return &pb.PermissionResponse{
+		HasPermission:  hasPermission,
+		Permissionship: int32(item.Permissionship),
+		Implementation: pb.Implementation_IMPLEMENTATION_SPICEDB,
+		MissingContext: missingContext,
+		CaveatName:     caveatName,
+	}
+}
@@ -3,21 +3,31 @@ package spicedb
 import (
 	"context"
 	"fmt"
+	"os"
 
+	"github.com/adityakumar/labs/go-entitlement-service/internal/caveat"
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
 	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/authzed-go/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type Client struct {
-	client *authzed.Client
+	client  *authzed.Client
+	caveats *caveat.Context
 }
 
-func NewClient() *Client {
+// NewClient builds a SpiceDB backend client and loads caveat parameter types
+// from the schema at schemaPath, so a PERMISSIONSHIP_CONDITIONAL result's
+// missing fields (see PartialCaveatInfo below) can be attributed back to the
+// caveat that declares them.
+func NewClient(schemaPath string) (*Client, error) {
 	// Add preshared key authentication
 	authInterceptor := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer dev-key")
@@ -32,7 +42,17 @@ func NewClient() *Client {
 	if err != nil {
 		panic(fmt.Sprintf("failed to create SpiceDB client: %v", err))
 	}
-	return &Client{client: cli}
+
+	schemaBytes, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caveat schema from %s: %w", schemaPath, err)
+	}
+	caveats, err := caveat.Load(string(schemaBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load caveat schema: %w", err)
+	}
+
+	return &Client{client: cli, caveats: caveats}, nil
 }
 
 func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
@@ -49,11 +69,7 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 				ObjectId:   req.GetActor(),
 			},
 		},
-		Consistency: &v1.Consistency{
-			Requirement: &v1.Consistency_FullyConsistent{
-				FullyConsistent: true,
-			},
-		},
+		Consistency: toSpiceDBConsistency(req.Consistency),
 	}
 
 	// Add context if provided
@@ -65,7 +81,8 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 		}
 		contextStruct, err := structpb.NewStruct(contextMap)
 		if err != nil {
-			return &pb.PermissionResponse{HasPermission: false, ErrorMessage: fmt.Sprintf("failed to create context: %v", err)}, err
+			typedErr := entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+			return errorResponse(typedErr), typedErr
 		}
 		checkReq.Context = contextStruct
 	}
@@ -75,7 +92,12 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 
 	resp, err := c.client.CheckPermission(ctx, checkReq)
 	if err != nil {
-		return &pb.PermissionResponse{HasPermission: false, ErrorMessage: err.Error()}, err
+		typedErr := entitlementerrors.New(classifyGRPCError(err), err)
+		return errorResponse(typedErr), typedErr
+	}
+	checkedAt := ""
+	if resp.CheckedAt != nil {
+		checkedAt = resp.CheckedAt.Token
 	}
 
 	// Log the response for debugging
@@ -86,28 +108,114 @@ func (c *Client) CheckPermission(ctx context.Context, req *pb.PermissionRequest)
 	switch resp.Permissionship {
 	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
 		hasPermission = true
-	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
-		// Conditional permission means the caveat conditions were met with the provided context
-		// This is equivalent to having permission
-		hasPermission = true
 	case v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION:
 		hasPermission = false
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		// SpiceDB couldn't fully evaluate the caveat with the context it was
+		// given - that's neither a grant nor a denial, so it's reported as
+		// its own first-class result rather than collapsed to one or the
+		// other (see missingContext below).
+		hasPermission = false
 	default:
 		hasPermission = false
 	}
 
-	// If we got conditional permission but didn't provide context, that's an error
-	if resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION &&
-		(req.Context == nil || len(req.Context) == 0) {
-		return &pb.PermissionResponse{
-			HasPermission:  false,
-			Permissionship: int32(resp.Permissionship),
-			ErrorMessage:   "conditional permission requires context but none was provided",
-		}, nil
-	}
+	missingContext, caveatName := c.missingContext(req.GetPermission(), resp.PartialCaveatInfo)
 
 	return &pb.PermissionResponse{
 		HasPermission:  hasPermission,
 		Permissionship: int32(resp.Permissionship),
+		CheckedAt:      checkedAt,
+		MissingContext: missingContext,
+		CaveatName:     caveatName,
 	}, nil
 }
+
+// missingContext extracts PartialCaveatInfo's missing caveat fields, if any,
+// and resolves the caveat they belong to from the loaded schema, using
+// permission to disambiguate a field declared by more than one caveat. It
+// returns (nil, "") when info is nil (i.e. the result wasn't conditional).
+func (c *Client) missingContext(permission string, info *v1.PartialCaveatInfo) ([]string, string) {
+	if info == nil || len(info.MissingRequiredContext) == 0 {
+		return nil, ""
+	}
+	caveatName := ""
+	if c.caveats != nil {
+		caveatName = c.caveats.CaveatForField(permission, info.MissingRequiredContext[0])
+	}
+	return info.MissingRequiredContext, caveatName
+}
+
+// toSpiceDBConsistency maps our portable Consistency oneof onto SpiceDB's
+// native v1.Consistency. A nil/empty requirement preserves the service's
+// original default of fully-consistent reads.
+func toSpiceDBConsistency(c *pb.Consistency) *v1.Consistency {
+	switch req := c.GetRequirement().(type) {
+	case *pb.Consistency_AtLeastAsFresh:
+		return &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: &v1.ZedToken{Token: req.AtLeastAsFresh},
+		}}
+	case *pb.Consistency_AtExactSnapshot:
+		return &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{
+			AtExactSnapshot: &v1.ZedToken{Token: req.AtExactSnapshot},
+		}}
+	case *pb.Consistency_MinimizeLatency:
+		return &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: req.MinimizeLatency}}
+	default:
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+}
+
+// errorResponse builds a PermissionResponse carrying err's taxonomy code and
+// message, for backends that need to return both a response and an error.
+func errorResponse(err *entitlementerrors.Error) *pb.PermissionResponse {
+	return &pb.PermissionResponse{
+		HasPermission: false,
+		ErrorMessage:  err.Error(),
+		ErrorCode:     toProtoErrorCode(err.Code),
+	}
+}
+
+func toProtoErrorCode(code entitlementerrors.Code) pb.ErrorCode {
+	switch code {
+	case entitlementerrors.ErrValidationFailed:
+		return pb.ErrorCode_ERROR_CODE_VALIDATION_FAILED
+	case entitlementerrors.ErrBackendUnavailable:
+		return pb.ErrorCode_ERROR_CODE_BACKEND_UNAVAILABLE
+	case entitlementerrors.ErrSchemaNotFound:
+		return pb.ErrorCode_ERROR_CODE_SCHEMA_NOT_FOUND
+	case entitlementerrors.ErrDeadlineExceeded:
+		return pb.ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED
+	case entitlementerrors.ErrCaveatContextInvalid:
+		return pb.ErrorCode_ERROR_CODE_CAVEAT_CONTEXT_INVALID
+	case entitlementerrors.ErrUnauthenticated:
+		return pb.ErrorCode_ERROR_CODE_UNAUTHENTICATED
+	case entitlementerrors.ErrConflict:
+		return pb.ErrorCode_ERROR_CODE_CONFLICT
+	default:
+		return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+	}
+}
+
+// classifyGRPCError maps a SpiceDB gRPC failure onto our taxonomy so callers
+// don't have to special-case authzed-go's status codes themselves.
+func classifyGRPCError(err error) entitlementerrors.Code {
+	st, ok := status.FromError(err)
+	if !ok {
+		return entitlementerrors.ErrBackendUnavailable
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return entitlementerrors.ErrSchemaNotFound
+	case codes.DeadlineExceeded:
+		return entitlementerrors.ErrDeadlineExceeded
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return entitlementerrors.ErrUnauthenticated
+	case codes.InvalidArgument:
+		return entitlementerrors.ErrValidationFailed
+	case codes.Aborted, codes.AlreadyExists:
+		return entitlementerrors.ErrConflict
+	default:
+		return entitlementerrors.ErrBackendUnavailable
+	}
+}
@@ -0,0 +1,127 @@
+package spicedb
+
+import (
+	"context"
+	"io"
+
+	entitlementerrors "github.com/adityakumar/labs/go-entitlement-service/internal/errors"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// LookupResources aggregates SpiceDB's LookupResources stream into a single
+// slice - the inverse of CheckPermission: "which resourceType objects can
+// actor do permission on" instead of "can actor do permission on resource".
+func (c *Client) LookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency) ([]*pb.LookupResourcesItem, error) {
+	var items []*pb.LookupResourcesItem
+	err := c.StreamLookupResources(ctx, actor, resourceType, permission, caveatContext, consistency, func(item *pb.LookupResourcesItem) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// StreamLookupResources calls onItem once per resource as SpiceDB's
+// LookupResources stream yields it, for callers that want to act on results
+// before the full lookup completes instead of waiting for LookupResources to
+// buffer them all. The stream is abandoned as soon as onItem returns an
+// error, which StreamLookupResources then returns to its caller.
+func (c *Client) StreamLookupResources(ctx context.Context, actor, resourceType, permission string, caveatContext map[string]string, consistency *pb.Consistency, onItem func(*pb.LookupResourcesItem) error) error {
+	contextStruct, err := contextToStruct(caveatContext)
+	if err != nil {
+		return entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+	}
+
+	stream, err := c.client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		ResourceObjectType: resourceType,
+		Permission:         permission,
+		Subject: &v1.SubjectReference{
+			Object: &v1.ObjectReference{ObjectType: "user", ObjectId: actor},
+		},
+		Context:     contextStruct,
+		Consistency: toSpiceDBConsistency(consistency),
+	})
+	if err != nil {
+		return entitlementerrors.New(classifyGRPCError(err), err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return entitlementerrors.New(classifyGRPCError(err), err)
+		}
+		if err := onItem(&pb.LookupResourcesItem{
+			ResourceId:     resp.ResourceObjectId,
+			Permissionship: lookupPermissionshipToProto(resp.Permissionship),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// LookupSubjects aggregates SpiceDB's LookupSubjects stream into a single
+// slice of subject ids - "who can do permission on resourceType:resourceID",
+// the inverse of LookupResources.
+func (c *Client) LookupSubjects(ctx context.Context, resourceType, resourceID, permission, subjectType string, caveatContext map[string]string, consistency *pb.Consistency) ([]string, error) {
+	contextStruct, err := contextToStruct(caveatContext)
+	if err != nil {
+		return nil, entitlementerrors.New(entitlementerrors.ErrCaveatContextInvalid, err)
+	}
+
+	stream, err := c.client.LookupSubjects(ctx, &v1.LookupSubjectsRequest{
+		Resource: &v1.ObjectReference{
+			ObjectType: resourceType,
+			ObjectId:   resourceID,
+		},
+		Permission:        permission,
+		SubjectObjectType: subjectType,
+		Context:           contextStruct,
+		Consistency:       toSpiceDBConsistency(consistency),
+	})
+	if err != nil {
+		return nil, entitlementerrors.New(classifyGRPCError(err), err)
+	}
+
+	var subjectIDs []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return subjectIDs, nil
+		}
+		if err != nil {
+			return nil, entitlementerrors.New(classifyGRPCError(err), err)
+		}
+		if resp.Subject != nil {
+			subjectIDs = append(subjectIDs, resp.Subject.SubjectObjectId)
+		}
+	}
+}
+
+func lookupPermissionshipToProto(p v1.LookupPermissionship) pb.Permissionship {
+	switch p {
+	case v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION:
+		return pb.Permissionship_PERMISSIONSHIP_HAS_PERMISSION
+	case v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		return pb.Permissionship_PERMISSIONSHIP_CONDITIONAL_PERMISSION
+	default:
+		return pb.Permissionship_PERMISSIONSHIP_UNSPECIFIED
+	}
+}
+
+// contextToStruct converts the proto's map[string]string caveat context into
+// the structpb.Struct SpiceDB's API expects, returning nil for an empty map
+// so an absent context isn't sent as an empty-but-present struct.
+func contextToStruct(caveatContext map[string]string) (*structpb.Struct, error) {
+	if len(caveatContext) == 0 {
+		return nil, nil
+	}
+	contextMap := make(map[string]interface{}, len(caveatContext))
+	for k, v := range caveatContext {
+		contextMap[k] = v
+	}
+	return structpb.NewStruct(contextMap)
+}
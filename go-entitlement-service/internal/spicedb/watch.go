@@ -0,0 +1,128 @@
+package spicedb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// Watch subscribes to SpiceDB's Watch API for relationship updates touching
+// any resource named by targets, and re-checks each affected target whenever
+// one of its relationships changes. An event is only sent when a target's
+// effective verdict actually flips, so a burst of unrelated writes doesn't
+// produce a burst of client-visible events.
+func (c *Client) Watch(ctx context.Context, targets []*pb.WatchTarget) (<-chan *pb.PermissionEvent, error) {
+	resourceTypes := watchedResourceTypes(targets)
+
+	stream, err := c.client.Watch(ctx, &v1.WatchRequest{
+		OptionalObjectTypes: resourceTypes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SpiceDB watch: %w", err)
+	}
+
+	events := make(chan *pb.PermissionEvent)
+	lastVerdict := make(map[*pb.WatchTarget]bool, len(targets))
+
+	// Establish the initial verdict for each target so the first change that
+	// touches it is evaluated as a flip, not assumed to be "new".
+	for _, target := range targets {
+		has, err := c.currentVerdict(ctx, target)
+		if err == nil {
+			lastVerdict[target] = has
+		}
+	}
+
+	go func() {
+		defer close(events)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if len(resp.Updates) == 0 {
+				continue
+			}
+
+			touched := touchedResources(resp.Updates)
+			for _, target := range targets {
+				if !touched[target.Resource] {
+					continue
+				}
+				has, err := c.currentVerdict(ctx, target)
+				if err != nil {
+					continue
+				}
+				if prev, ok := lastVerdict[target]; ok && prev == has {
+					continue
+				}
+				lastVerdict[target] = has
+				select {
+				case events <- &pb.PermissionEvent{
+					Actor:           target.Actor,
+					Resource:        target.Resource,
+					Permission:      target.Permission,
+					HasPermission:   has,
+					Implementation:  pb.Implementation_IMPLEMENTATION_SPICEDB,
+					ChangedAtUnixMs: time.Now().UnixMilli(),
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (c *Client) currentVerdict(ctx context.Context, target *pb.WatchTarget) (bool, error) {
+	resp, err := c.CheckPermission(ctx, &pb.PermissionRequest{
+		Actor:      target.Actor,
+		Resource:   target.Resource,
+		Permission: target.Permission,
+		Context:    target.Context,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.HasPermission, nil
+}
+
+func watchedResourceTypes(targets []*pb.WatchTarget) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, target := range targets {
+		objType, _ := splitObjectRef(target.Resource)
+		if objType == "" || seen[objType] {
+			continue
+		}
+		seen[objType] = true
+		types = append(types, objType)
+	}
+	return types
+}
+
+func touchedResources(updates []*v1.RelationshipUpdate) map[string]bool {
+	touched := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		res := u.GetRelationship().GetResource()
+		if res == nil {
+			continue
+		}
+		touched[fmt.Sprintf("%s:%s", res.ObjectType, res.ObjectId)] = true
+	}
+	return touched
+}
+
+func splitObjectRef(ref string) (string, string) {
+	for i, r := range ref {
+		if r == ':' {
+			return ref[:i], ref[i+1:]
+		}
+	}
+	return "", ref
+}
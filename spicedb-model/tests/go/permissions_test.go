@@ -1,27 +1,34 @@
 package main
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+	"github.com/adityakumar/labs/go-entitlement-service/internal/spicedb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
-// PermissionsTestSuite contains all permission tests
+// PermissionsTestSuite contains all permission tests. It runs unchanged
+// against whichever Backend newClient constructs, so the same Emma/Adi/Alice
+// test cases exercise SpiceDB, Neo4j, and GraphQL identically.
 type PermissionsTestSuite struct {
 	suite.Suite
-	client *TestClient
+	newClient func() (Backend, error)
+	client    Backend
 }
 
 // SetupSuite runs once before all tests
 func (suite *PermissionsTestSuite) SetupSuite() {
-	client, err := NewTestClient()
+	client, err := suite.newClient()
 	require.NoError(suite.T(), err, "Failed to create test client")
 	suite.client = client
 
-	// Wait a moment for SpiceDB to be ready
+	// Wait a moment for the backend to be ready
 	time.Sleep(2 * time.Second)
 }
 
@@ -291,7 +298,57 @@ func (suite *PermissionsTestSuite) TestPOAViewAndDownloadPermissions() {
 	}
 }
 
-// TestRunAllPermissionTests runs the test suite
+// TestRunAllPermissionTests runs the full permissions suite once per
+// backend, so a regression that only affects one backend's translation of
+// the fixtures (SpiceDB relationships, Neo4j's flattened graph, or GraphQL's
+// resolvers) fails that backend's subtest without masking the others.
 func TestRunAllPermissionTests(t *testing.T) {
-	suite.Run(t, new(PermissionsTestSuite))
+	backends := []struct {
+		name      string
+		newClient func() (Backend, error)
+	}{
+		{"spicedb", func() (Backend, error) { return NewTestClient() }},
+		{"neo4j", func() (Backend, error) { return NewNeo4jTestClient() }},
+		{"graphql", func() (Backend, error) { return NewGraphQLTestClient() }},
+	}
+
+	for _, b := range backends {
+		b := b
+		t.Run(b.name, func(t *testing.T) {
+			suite.Run(t, &PermissionsTestSuite{newClient: b.newClient})
+		})
+	}
+}
+
+// TestEmmasPOAMissingContext exercises the conditional-response path: asking
+// whether Emma can initiate a payment through her POA without saying
+// "amount" shouldn't flatten to a grant or an error the way the
+// Backend.CheckPermission bool return does - it should come back as
+// PERMISSIONSHIP_CONDITIONAL_PERMISSION naming exactly the field SpiceDB
+// couldn't resolve the caveat without, and which caveat that is.
+func TestEmmasPOAMissingContext(t *testing.T) {
+	testClient, err := NewTestClient()
+	require.NoError(t, err, "Failed to create test client")
+	defer testClient.Close()
+	require.NoError(t, SetupTestData(testClient), "Failed to setup test data")
+
+	client, err := spicedb.NewClient(filepath.Join("..", "..", "model.zaml"))
+	require.NoError(t, err, "Failed to create SpiceDB client")
+
+	resp, err := client.CheckPermission(context.Background(), &pb.PermissionRequest{
+		Actor:      "emma",
+		Resource:   "acc123",
+		Permission: "can_initiate_payment",
+		Context: map[string]string{
+			// "amount" intentionally omitted; "now" alone isn't enough to
+			// resolve the within_time_and_limit caveat.
+			"now": "2025-03-15T00:00:00Z",
+		},
+	})
+
+	require.NoError(t, err, "Permission check should not error")
+	assert.False(t, resp.HasPermission)
+	assert.Equal(t, pb.Permissionship_PERMISSIONSHIP_CONDITIONAL_PERMISSION, pb.Permissionship(resp.Permissionship))
+	assert.Equal(t, []string{"amount"}, resp.MissingContext)
+	assert.Equal(t, "within_time_and_limit", resp.CaveatName)
 }
\ No newline at end of file
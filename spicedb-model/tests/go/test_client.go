@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/adityakumar/labs/go-entitlement-service/internal/caveat"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/authzed-go/v1"
 	"google.golang.org/grpc"
@@ -15,8 +16,9 @@ import (
 
 // TestClient wraps the SpiceDB client with test utilities
 type TestClient struct {
-	client *authzed.Client
-	ctx    context.Context
+	client  *authzed.Client
+	ctx     context.Context
+	caveats *caveat.Context
 }
 
 // NewTestClient creates a new test client connected to SpiceDB
@@ -76,32 +78,52 @@ func (tc *TestClient) ClearAllData() error {
 	return nil
 }
 
-// LoadSchema loads the schema from model.zaml content
+// LoadSchema loads the schema from model.zaml content and parses its caveat
+// declarations, so CreateRelationship and CheckPermission can type-check
+// caveat context against them instead of trusting it blindly.
 func (tc *TestClient) LoadSchema(schemaContent string) error {
 	req := &v1.WriteSchemaRequest{
 		Schema: schemaContent,
 	}
-	
+
 	_, err := tc.client.WriteSchema(tc.ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to write schema: %w", err)
 	}
-	
+
+	caveats, err := caveat.Load(schemaContent)
+	if err != nil {
+		return fmt.Errorf("failed to parse caveat declarations: %w", err)
+	}
+	tc.caveats = caveats
+
 	return nil
 }
 
 // CreateRelationship creates a single relationship
 func (tc *TestClient) CreateRelationship(resource, relation, subject string, caveat map[string]interface{}) error {
+	_, err := tc.createRelationship(resource, relation, subject, caveat)
+	return err
+}
+
+// CreateRelationshipAndGetToken creates a single relationship and returns the
+// write's ZedToken, so a test can immediately check with
+// AtLeastAsFresh(token) instead of racing a default minimize_latency read.
+func (tc *TestClient) CreateRelationshipAndGetToken(resource, relation, subject string, caveat map[string]interface{}) (string, error) {
+	return tc.createRelationship(resource, relation, subject, caveat)
+}
+
+func (tc *TestClient) createRelationship(resource, relation, subject string, caveat map[string]interface{}) (string, error) {
 	// Parse resource
 	resourceParts := parseObjectRef(resource)
 	if len(resourceParts) != 2 {
-		return fmt.Errorf("invalid resource format: %s", resource)
+		return "", fmt.Errorf("invalid resource format: %s", resource)
 	}
-	
+
 	// Parse subject
 	subjectParts := parseObjectRef(subject)
 	if len(subjectParts) != 2 {
-		return fmt.Errorf("invalid subject format: %s", subject)
+		return "", fmt.Errorf("invalid subject format: %s", subject)
 	}
 	
 	relationship := &v1.Relationship{
@@ -120,9 +142,14 @@ func (tc *TestClient) CreateRelationship(resource, relation, subject string, cav
 	
 	// Add caveat if provided
 	if caveat != nil && len(caveat) > 0 {
+		caveatName := getCaveatName(relation)
+		caveatStruct, err := buildCaveatStruct(tc.caveats, caveatName, caveat)
+		if err != nil {
+			return "", err
+		}
 		relationship.OptionalCaveat = &v1.ContextualizedCaveat{
-			CaveatName: getCaveatName(relation),
-			Context:    convertToStruct(caveat),
+			CaveatName: caveatName,
+			Context:    caveatStruct,
 		}
 	}
 	
@@ -135,16 +162,38 @@ func (tc *TestClient) CreateRelationship(resource, relation, subject string, cav
 		},
 	}
 	
-	_, err := tc.client.WriteRelationships(tc.ctx, req)
+	resp, err := tc.client.WriteRelationships(tc.ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create relationship %s %s %s: %w", resource, relation, subject, err)
+		return "", fmt.Errorf("failed to create relationship %s %s %s: %w", resource, relation, subject, err)
 	}
-	
-	return nil
+
+	var token string
+	if resp.GetWrittenAt() != nil {
+		token = resp.GetWrittenAt().GetToken()
+	}
+	return token, nil
 }
 
-// CheckPermission checks if a subject has permission on a resource
+// CheckPermission checks if a subject has permission on a resource with no
+// Consistency set, so SpiceDB answers with its minimize_latency default.
 func (tc *TestClient) CheckPermission(resource, permission, subject string, caveatContext map[string]interface{}) (bool, error) {
+	return tc.checkPermission(resource, permission, subject, caveatContext, nil)
+}
+
+// CheckPermissionAtLeastAsFresh checks a permission with a Consistency
+// requirement that the backend's view reflect at least writtenAtToken, so a
+// test can write then immediately check without a race against
+// minimize_latency's default staleness window.
+func (tc *TestClient) CheckPermissionAtLeastAsFresh(resource, permission, subject string, caveatContext map[string]interface{}, writtenAtToken string) (bool, error) {
+	consistency := &v1.Consistency{
+		Requirement: &v1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: &v1.ZedToken{Token: writtenAtToken},
+		},
+	}
+	return tc.checkPermission(resource, permission, subject, caveatContext, consistency)
+}
+
+func (tc *TestClient) checkPermission(resource, permission, subject string, caveatContext map[string]interface{}, consistency *v1.Consistency) (bool, error) {
 	// Parse resource and subject
 	resourceParts := parseObjectRef(resource)
 	if len(resourceParts) != 2 {
@@ -168,13 +217,18 @@ func (tc *TestClient) CheckPermission(resource, permission, subject string, cave
 				ObjectId:   subjectParts[1],
 			},
 		},
+		Consistency: consistency,
 	}
-	
+
 	// Add caveat context if provided
 	if caveatContext != nil && len(caveatContext) > 0 {
-		req.Context = convertToStruct(caveatContext)
+		contextStruct, err := tc.caveats.ValidateContext(caveatContext)
+		if err != nil {
+			return false, err
+		}
+		req.Context = contextStruct
 	}
-	
+
 	resp, err := tc.client.CheckPermission(tc.ctx, req)
 	if err != nil {
 		return false, fmt.Errorf("failed to check permission: %w", err)
@@ -183,6 +237,17 @@ func (tc *TestClient) CheckPermission(resource, permission, subject string, cave
 	return resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil
 }
 
+// Backend is the set of operations PermissionsTestSuite needs from a test
+// client, so the same test cases can run against SpiceDB, Neo4j, or GraphQL
+// without duplicating the suite. *TestClient already satisfies it.
+type Backend interface {
+	Close() error
+	ClearAllData() error
+	LoadSchema(schemaContent string) error
+	CreateRelationship(resource, relation, subject string, caveat map[string]interface{}) error
+	CheckPermission(resource, permission, subject string, caveatContext map[string]interface{}) (bool, error)
+}
+
 // Helper functions
 
 func parseObjectRef(objectRef string) []string {
@@ -195,6 +260,14 @@ func parseObjectRef(objectRef string) []string {
 	return []string{objectRef}
 }
 
+// objectID returns the id half of a "type:id" object reference, for callers
+// (Neo4j, GraphQL) whose native APIs address objects by bare id rather than
+// SpiceDB's typed reference.
+func objectID(objectRef string) string {
+	parts := parseObjectRef(objectRef)
+	return parts[len(parts)-1]
+}
+
 func getCaveatName(relation string) string {
 	// Map relations to their caveat names based on the schema
 	caveatMap := map[string]string{
@@ -211,11 +284,14 @@ func getCaveatName(relation string) string {
 	return ""
 }
 
-func convertToStruct(data map[string]interface{}) *structpb.Struct {
-	s, err := structpb.NewStruct(data)
-	if err != nil {
-		// Fallback to empty struct if conversion fails
-		return &structpb.Struct{}
+// buildCaveatStruct flattens a relationship's caveat data into the kv pairs
+// caveat.Context.Build expects, type-checking each value against
+// caveatName's declared parameters instead of dumping the map straight
+// through structpb.NewStruct.
+func buildCaveatStruct(caveats *caveat.Context, caveatName string, data map[string]interface{}) (*structpb.Struct, error) {
+	kv := make([]interface{}, 0, len(data)*2)
+	for k, v := range data {
+		kv = append(kv, k, v)
 	}
-	return s
+	return caveats.Build(caveatName, kv...)
 }
\ No newline at end of file
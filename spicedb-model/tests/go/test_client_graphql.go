@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	graphqlbackend "github.com/adityakumar/labs/go-entitlement-service/internal/graphql"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// GraphQLTestClient adapts internal/graphql.Client to the Backend interface.
+// GraphQL is a read-only query layer over the same Neo4j store the server
+// wires up alongside it (see cmd/server/main.go), so fixture loading and
+// clearing are delegated to a Neo4jTestClient rather than reimplemented.
+type GraphQLTestClient struct {
+	*Neo4jTestClient
+	client *graphqlbackend.Client
+}
+
+// NewGraphQLTestClient creates a new test client for the GraphQL backend,
+// backed by the same Neo4j store its fixtures are loaded into.
+func NewGraphQLTestClient() (*GraphQLTestClient, error) {
+	neo4jClient, err := NewNeo4jTestClient()
+	if err != nil {
+		return nil, err
+	}
+
+	schemaPath := filepath.Join("..", "..", "model.zaml")
+	client, err := graphqlbackend.NewClient(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL client: %w", err)
+	}
+
+	return &GraphQLTestClient{Neo4jTestClient: neo4jClient, client: client}, nil
+}
+
+func (tc *GraphQLTestClient) CheckPermission(resource, permission, subject string, caveatContext map[string]interface{}) (bool, error) {
+	resp, err := tc.client.CheckPermission(context.Background(), &pb.PermissionRequest{
+		Actor:      objectID(subject),
+		Resource:   objectID(resource),
+		Permission: permission,
+		Context:    stringifyContext(caveatContext),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.HasPermission, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adityakumar/labs/go-entitlement-service/internal/backend"
+	neo4jbackend "github.com/adityakumar/labs/go-entitlement-service/internal/neo4j"
+	pb "github.com/adityakumar/labs/go-entitlement-service/internal/pb/entitlement-service/proto"
+)
+
+// Neo4jTestClient adapts internal/neo4j.Client to the Backend interface so
+// the same permission test cases that exercise SpiceDB can run against
+// Neo4j's flattened graph model instead.
+type Neo4jTestClient struct {
+	client *neo4jbackend.Client
+	ctx    context.Context
+}
+
+// NewNeo4jTestClient creates a new test client connected to Neo4j.
+func NewNeo4jTestClient() (*Neo4jTestClient, error) {
+	client, err := neo4jbackend.NewClient("bolt://localhost:7687", "neo4j", "password", neo4jbackend.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Neo4j client: %w", err)
+	}
+	return &Neo4jTestClient{client: client, ctx: context.Background()}, nil
+}
+
+func (tc *Neo4jTestClient) Close() error {
+	return tc.client.Close()
+}
+
+func (tc *Neo4jTestClient) ClearAllData() error {
+	return tc.client.ClearAll(tc.ctx)
+}
+
+func (tc *Neo4jTestClient) LoadSchema(schemaContent string) error {
+	return tc.client.LoadSchema(tc.ctx, schemaContent)
+}
+
+func (tc *Neo4jTestClient) CreateRelationship(resource, relation, subject string, caveat map[string]interface{}) error {
+	return tc.client.WriteRelationships(tc.ctx, []backend.Relationship{
+		{Resource: resource, Relation: relation, Subject: subject, Caveat: caveat},
+	})
+}
+
+func (tc *Neo4jTestClient) CheckPermission(resource, permission, subject string, caveatContext map[string]interface{}) (bool, error) {
+	resp, err := tc.client.CheckPermission(tc.ctx, &pb.PermissionRequest{
+		Actor:      objectID(subject),
+		Resource:   objectID(resource),
+		Permission: permission,
+		Context:    stringifyContext(caveatContext),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.HasPermission, nil
+}
+
+// stringifyContext converts a permission test's caveat context (Go-typed
+// values, as SpiceDB's structpb-backed check expects) into the map[string]string
+// Neo4j's Cypher parameters use, renaming "now" to "test_date", the context
+// key neo4j.Client.CheckPermission reads and passes to the query as $now.
+func stringifyContext(caveatContext map[string]interface{}) map[string]string {
+	context := make(map[string]string, len(caveatContext))
+	for k, v := range caveatContext {
+		key := k
+		if key == "now" {
+			key = "test_date"
+		}
+		context[key] = fmt.Sprintf("%v", v)
+	}
+	return context
+}
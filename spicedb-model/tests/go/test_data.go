@@ -1,118 +1,25 @@
 package main
 
 import (
-	"io/ioutil"
 	"path/filepath"
-)
-
-// TestData holds all test relationships and schema
-type TestData struct {
-	Schema        string
-	Relationships []TestRelationship
-}
 
-// TestRelationship represents a relationship to be loaded
-type TestRelationship struct {
-	Resource string
-	Relation string
-	Subject  string
-	Caveat   map[string]interface{}
-}
-
-// LoadTestData loads schema and relationships for testing
-func LoadTestData() (*TestData, error) {
-	// Load schema from model.zaml (go up two directories to reach project root)
-	schemaPath := filepath.Join("..", "..", "model.zaml")
-	schemaBytes, err := ioutil.ReadFile(schemaPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Define test relationships matching tuples.csv
-	relationships := []TestRelationship{
-		{
-			Resource: "account:acc123",
-			Relation: "owner",
-			Subject:  "user:david",
-			Caveat:   nil,
-		},
-		{
-			Resource: "account:acc456",
-			Relation: "owner",
-			Subject:  "user:emma",
-			Caveat:   nil,
-		},
-		{
-			Resource: "account:acc123",
-			Relation: "delegated_access",
-			Subject:  "poa:poa1",
-			Caveat:   nil,
-		},
-		{
-			Resource: "poa:poa1",
-			Relation: "delegate_with_time_and_limit",
-			Subject:  "user:emma",
-			Caveat: map[string]interface{}{
-				"max_amount": 5000,
-				"start":      "2025-01-01T00:00:00Z",
-				"end":        "2025-06-30T23:59:59Z",
-			},
-		},
-		{
-			Resource: "account:acc456",
-			Relation: "accountant_access",
-			Subject:  "user:adi",
-			Caveat: map[string]interface{}{
-				"start": "2025-01-01T00:00:00Z",
-				"end":   "2025-03-31T23:59:59Z",
-			},
-		},
-		{
-			Resource: "account:acc789",
-			Relation: "owner",
-			Subject:  "org:abc",
-			Caveat:   nil,
-		},
-		{
-			Resource: "account:acc790",
-			Relation: "owner",
-			Subject:  "org:abc",
-			Caveat:   nil,
-		},
-		{
-			Resource: "account:acc791",
-			Relation: "owner",
-			Subject:  "org:abc",
-			Caveat:   nil,
-		},
-		{
-			Resource: "account:acc792",
-			Relation: "owner",
-			Subject:  "org:abc",
-			Caveat:   nil,
-		},
-		{
-			Resource: "role:finance_ops",
-			Relation: "member",
-			Subject:  "user:alice",
-			Caveat:   nil,
-		},
-		{
-			Resource: "org:abc",
-			Relation: "member",
-			Subject:  "role:finance_ops",
-			Caveat:   nil,
-		},
-	}
+	"github.com/adityakumar/labs/go-entitlement-service/internal/fixtures"
+)
 
-	return &TestData{
-		Schema:        string(schemaBytes),
-		Relationships: relationships,
-	}, nil
+// LoadTestData loads the schema and relationship fixtures (model.zaml,
+// tuples.csv, relationships.yaml) shared with the LoadFixtures RPC, so the
+// integration suite and production re-seeding always agree on what
+// "Emma/David/Adi/Alice/org:abc/role:finance_ops" means.
+func LoadTestData() (*fixtures.Set, error) {
+	return fixtures.Load(
+		filepath.Join("..", "..", "model.zaml"),
+		filepath.Join("..", "..", "tuples.csv"),
+		filepath.Join("..", "..", "relationships.yaml"),
+	)
 }
 
 // SetupTestData clears existing data and loads fresh test data
-func SetupTestData(client *TestClient) error {
+func SetupTestData(client Backend) error {
 	// Load test data
 	testData, err := LoadTestData()
 	if err != nil {
@@ -137,4 +44,4 @@ func SetupTestData(client *TestClient) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}